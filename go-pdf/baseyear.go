@@ -0,0 +1,170 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is what changed since the base year in a way that may call for
+// the base year to be recalculated.
+type ChangeKind int
+
+const (
+	Acquisition     ChangeKind = iota // facilities or activities added, see 3.1.5
+	Divestment                        // facilities or activities removed
+	Methodology                       // the calculation method changed
+	EmissionFactor                    // an emission factor changed
+	ErrorCorrection                   // a significant error was found
+)
+
+var changeKindNames = []string{
+	Acquisition:     "เพิ่ม Facility/กิจกรรม",
+	Divestment:      "ลด Facility/กิจกรรม",
+	Methodology:     "เปลี่ยนวิธีการคำนวณ",
+	EmissionFactor:  "เปลี่ยนค่า EF",
+	ErrorCorrection: "แก้ไขข้อผิดพลาด",
+}
+
+func (k ChangeKind) String() string {
+	if k < Acquisition || k > ErrorCorrection {
+		return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return changeKindNames[k]
+}
+
+// BaseYearChange is a change to the base year emissions of a source in
+// tCO2e, e.g. what an acquired facility emitted in the base year, or the
+// difference a new emission factor makes.
+type BaseYearChange struct {
+	Kind   ChangeKind
+	Scope  int
+	Source string
+	Delta  float64
+	Reason string
+}
+
+// BaseYear is the base year of section 6: the inventory as verified and the
+// changes since. The base year is recalculated when the changes together
+// move its emissions by at least Threshold, a fraction such as 0.05, of its
+// total; increases and decreases both count, so they cannot cancel out.
+// Coverage explains why scopes of the report year are missing from the base
+// year when their base year emissions are not known.
+type BaseYear struct {
+	Period    string
+	Original  Inventory
+	Threshold float64
+	Changes   []BaseYearChange
+	Coverage  string
+}
+
+// effect returns the size of all changes, each counted as an absolute
+// value, as a fraction of the original total.
+func (b BaseYear) effect() float64 {
+	total := b.Original.ScopeTotal(0)
+	if total == 0 {
+		return 0
+	}
+	delta := 0.0
+	for _, c := range b.Changes {
+		delta += math.Abs(c.Delta)
+	}
+	return delta / total
+}
+
+// recalculate reports whether the changes reach the threshold.
+func (b BaseYear) recalculate() bool {
+	return len(b.Changes) > 0 && b.effect() >= b.Threshold
+}
+
+// restated returns the base year inventory to compare against: the
+// original with the changes applied when they reach the threshold.
+func (b BaseYear) restated() Inventory {
+	if !b.recalculate() {
+		return b.Original
+	}
+	return b.applied()
+}
+
+// applied returns the original inventory with all changes applied.
+func (b BaseYear) applied() Inventory {
+	inv := Inventory{Year: b.Original.Year, Sources: append([]EmissionSource(nil), b.Original.Sources...)}
+	for _, c := range b.Changes {
+		i := sourceIndex(inv.Sources, c.Scope, c.Source)
+		if i < 0 {
+			inv.Sources = append(inv.Sources, EmissionSource{Scope: c.Scope, Name: c.Source})
+			i = len(inv.Sources) - 1
+		}
+		inv.Sources[i].Emissions += c.Delta
+	}
+	return inv
+}
+
+// sourceIndex returns the index of the source of scope named name, or -1.
+// Names match as in section 3.2, see sourceKey.
+func sourceIndex(sources []EmissionSource, scope int, name string) int {
+	for i, source := range sources {
+		if source.Scope == scope && sourceKey(source.Name) == sourceKey(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// baseYearTable returns the table of section 6.2: the original and the
+// recalculated emissions of each base year source with the changes that
+// apply to it. The recalculated column is "-" when no recalculation is
+// needed.
+func (b BaseYear) baseYearTable() [][]string {
+	data := [][]string{{
+		"ขอบเขตการดำเนินงาน",
+		"รายการแหล่งปล่อยก๊าซเรือนกระจก",
+		"ปีฐานเดิม (Ton CO2e)",
+		"ปีฐานคำนวณใหม่ (Ton CO2e)",
+		"หมายเหตุ",
+	}}
+	recalculate, applied := b.recalculate(), b.applied()
+	for scope := 1; scope <= 3; scope++ {
+		for _, source := range applied.Sources {
+			if source.Scope != scope {
+				continue
+			}
+			original := "-"
+			if o, ok := b.Original.source(scope, source.Name); ok {
+				original = formatNumber(o.Emissions, 2)
+			}
+			recalculated := "-"
+			if recalculate {
+				recalculated = formatNumber(source.Emissions, 2)
+			}
+			var reasons []string
+			for _, c := range b.Changes {
+				if c.Scope == scope && sourceKey(c.Source) == sourceKey(source.Name) {
+					reasons = append(reasons, c.Kind.String()+" "+signedNumber(c.Delta, 2)+": "+c.Reason)
+				}
+			}
+			data = append(data, []string{scopeName(scope), source.Name, original, recalculated, strings.Join(reasons, "\n")})
+		}
+	}
+	return data
+}
+
+// baseYearNotes returns the notes under the table of section 6.2: the
+// recalculation threshold, whether the changes reach it and why scopes are
+// missing from the base year.
+func (b BaseYear) baseYearNotes() *TableNotes {
+	notes := &TableNotes{Title: "หมายเหตุ"}
+	notes.Add("คำนวณปีฐานใหม่เมื่อการเปลี่ยนแปลงโครงสร้างองค์กร วิธีการคำนวณ ค่า EF หรือการแก้ไขข้อผิดพลาด รวมกันมีผลต่อปริมาณการปล่อยก๊าซเรือนกระจกของปีฐานตั้งแต่ร้อยละ " + formatPercent(b.Threshold) + " โดยนับขนาดของการเปลี่ยนแปลงแต่ละรายการทั้งที่เพิ่มขึ้นและลดลง")
+	switch {
+	case len(b.Changes) == 0:
+		notes.Add("ไม่มีการเปลี่ยนแปลงที่มีผลต่อปีฐาน")
+	case b.recalculate():
+		notes.Add("การเปลี่ยนแปลงมีผลร้อยละ " + formatNumber(100*b.effect(), 2) + " จึงคำนวณปีฐานใหม่")
+	default:
+		notes.Add("การเปลี่ยนแปลงมีผลร้อยละ " + formatNumber(100*b.effect(), 2) + " ไม่ถึงเกณฑ์ จึงไม่คำนวณปีฐานใหม่")
+	}
+	if coverage := strings.TrimSpace(b.Coverage); coverage != "" {
+		notes.Add(coverage)
+	}
+	return notes
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestBaseYearRecalculate(t *testing.T) {
+	original := Inventory{Year: 2564, Sources: []EmissionSource{
+		{Scope: 1, Name: "น้ำมันดีเซลรถยนต์", Emissions: 100},
+	}}
+	tests := []struct {
+		name    string
+		changes []BaseYearChange
+		effect  float64
+		want    bool
+	}{
+		{"no changes", nil, 0, false},
+		{"below threshold", []BaseYearChange{{Scope: 1, Source: "น้ำมันดีเซลรถยนต์", Delta: 4}}, 0.04, false},
+		{"changes that cancel out", []BaseYearChange{
+			{Kind: Acquisition, Scope: 1, Source: "1. น้ำมันดีเซลรถยนต์", Delta: 10},
+			{Kind: Divestment, Scope: 1, Source: "น้ำมันดีเซลรถยนต์", Delta: -10},
+		}, 0.2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := BaseYear{Original: original, Threshold: 0.05, Changes: tt.changes}
+			if got := b.effect(); got != tt.effect {
+				t.Errorf("effect() = %v, want %v", got, tt.effect)
+			}
+			if got := b.recalculate(); got != tt.want {
+				t.Errorf("recalculate() = %v, want %v", got, tt.want)
+			}
+			// Changes to a numbered name apply to the same source.
+			if n := len(b.applied().Sources); n != 1 {
+				t.Errorf("applied() has %d sources, want 1", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// CaptionKind tells figure captions from table captions.
+type CaptionKind int
+
+const (
+	FigureCaption CaptionKind = iota
+	TableCaption
+)
+
+func (k CaptionKind) prefix() string {
+	if k == TableCaption {
+		return "ตารางที่"
+	}
+	return "รูปที่"
+}
+
+// Captions numbers the figure and table captions of a report, e.g.
+// "รูปที่ 3-1", and collects them for the lists of figures and tables.
+//
+// Page numbers and references to captions further on are only known once
+// the report has been laid out, so the report is generated again while
+// changed reports a difference from the previous pass. Like gofpdf, it
+// keeps the first error of a pass for Err rather than stopping the layout.
+type Captions struct {
+	// PerChapter restarts the numbering in every chapter and prefixes the
+	// chapter number.
+	PerChapter bool
+
+	chapter  int
+	entries  []captionEntry
+	previous []captionEntry
+	err      error
+}
+
+type captionEntry struct {
+	kind    CaptionKind
+	chapter int
+	label   string
+	number  string
+	text    string
+	page    int
+}
+
+// Caption is a numbered caption returned by Captions.Figure or
+// Captions.Table. As a Block it is drawn centred above a table and, like a
+// heading, stays with it; figures take it through Image.Figure.
+type Caption struct {
+	captions *Captions
+	index    int
+}
+
+const (
+	captionListLineHeight = 10.0
+	captionListPageWidth  = 15.0
+)
+
+// begin starts a new pass over the report.
+func (c *Captions) begin() {
+	c.previous = c.entries
+	c.entries = nil
+	c.chapter = 0
+	c.err = nil
+}
+
+// Err returns the first error of the last pass, such as a label
+// registered twice.
+func (c *Captions) Err() error {
+	return c.err
+}
+
+// changed reports whether the last pass numbered or placed any caption
+// differently from the pass before it.
+func (c *Captions) changed() bool {
+	if len(c.entries) != len(c.previous) {
+		return true
+	}
+	for i := range c.entries {
+		if c.entries[i] != c.previous[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Chapter sets the chapter of the captions that follow.
+func (c *Captions) Chapter(n int) {
+	c.chapter = n
+}
+
+// Figure registers a figure caption under label and returns it. Labels are
+// shared by figures and tables so that Ref can find them; registering one
+// twice in a pass is an error, see Err.
+func (c *Captions) Figure(label, text string) *Caption {
+	return c.add(FigureCaption, label, text)
+}
+
+// Table registers a table caption under label and returns it, like Figure.
+func (c *Captions) Table(label, text string) *Caption {
+	return c.add(TableCaption, label, text)
+}
+
+func (c *Captions) add(kind CaptionKind, label, text string) *Caption {
+	n := 1
+	for _, entry := range c.entries {
+		if entry.label == label && c.err == nil {
+			c.err = fmt.Errorf("captions: label %q is registered twice", label)
+		}
+		if entry.kind == kind && (!c.PerChapter || entry.chapter == c.chapter) {
+			n++
+		}
+	}
+	number := strconv.Itoa(n)
+	if c.PerChapter {
+		number = strconv.Itoa(c.chapter) + "-" + number
+	}
+	c.entries = append(c.entries, captionEntry{kind: kind, chapter: c.chapter, label: label, number: number, text: text})
+	return &Caption{captions: c, index: len(c.entries) - 1}
+}
+
+// Ref returns the name of the caption registered under label, e.g.
+// "ตารางที่ 5-2", for use in text. Captions that come later in the report
+// are taken from the previous pass; "??" stands in until they are known.
+func (c *Captions) Ref(label string) string {
+	for _, entries := range [][]captionEntry{c.entries, c.previous} {
+		for _, entry := range entries {
+			if entry.label == label {
+				return entry.name()
+			}
+		}
+	}
+	return "??"
+}
+
+func (e captionEntry) name() string {
+	return e.kind.prefix() + " " + e.number
+}
+
+func (c *Caption) entry() *captionEntry {
+	return &c.captions.entries[c.index]
+}
+
+// String returns the caption as printed, e.g. "รูปที่ 3-1 แผนผังของโรงงาน".
+func (c *Caption) String() string {
+	return c.entry().name() + " " + c.entry().text
+}
+
+// place records the page the caption is drawn on.
+func (c *Caption) place(pdf *gofpdf.Fpdf) {
+	c.entry().page = pdf.PageNo() - 1
+}
+
+func (c *Caption) lines(pdf *gofpdf.Fpdf, w float64) []string {
+	pdf.SetFont("THSarabunNew", "B", captionFontSize)
+	return splitLines(pdf, c.String(), w)
+}
+
+func (c *Caption) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	return captionLineHeight * float64(len(c.lines(pdf, w)))
+}
+
+func (c *Caption) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return c.height(pdf, w)
+}
+
+func (c *Caption) render(pdf *gofpdf.Fpdf, w float64) {
+	left, _, _, _ := pdf.GetMargins()
+	c.place(pdf)
+	for _, line := range c.lines(pdf, w) {
+		pdf.SetX(left)
+		pdf.CellFormat(w, captionLineHeight, line, "", 1, "C", false, 0, "")
+	}
+}
+
+// generateCaptionLists draws the list of figures and the list of tables
+// from the previous pass, each on a new page. Nothing is drawn for a kind
+// without captions.
+func generateCaptionLists(pdf *gofpdf.Fpdf, captions *Captions) {
+	titles := map[CaptionKind]string{
+		FigureCaption: "สารบัญรูป",
+		TableCaption:  "สารบัญตาราง",
+	}
+	for _, kind := range []CaptionKind{FigureCaption, TableCaption} {
+		var entries []captionEntry
+		for _, entry := range captions.previous {
+			if entry.kind == kind {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		pdf.AddPage()
+		blocks := []Block{Heading{Text: titles[kind], Align: "C"}, Spacer{Height: 5}}
+		for _, entry := range entries {
+			blocks = append(blocks, captionListEntry(entry))
+		}
+		generateFlowContent(pdf, blocks...)
+	}
+}
+
+// captionListEntry is a line of a list of figures or tables: the caption,
+// dot leaders and the page number.
+type captionListEntry captionEntry
+
+func (e captionListEntry) lines(pdf *gofpdf.Fpdf, w float64) []string {
+	pdf.SetFont("THSarabunNew", "", 14)
+	return splitLines(pdf, captionEntry(e).name()+" "+e.text, w-captionListPageWidth)
+}
+
+func (e captionListEntry) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	return captionListLineHeight * float64(len(e.lines(pdf, w)))
+}
+
+func (e captionListEntry) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return e.height(pdf, w)
+}
+
+func (e captionListEntry) render(pdf *gofpdf.Fpdf, w float64) {
+	left, _, _, _ := pdf.GetMargins()
+	lines := e.lines(pdf, w)
+	for i, line := range lines {
+		pdf.SetX(left)
+		if i < len(lines)-1 {
+			pdf.CellFormat(w, captionListLineHeight, line, "", 1, "L", false, 0, "")
+			continue
+		}
+		// Fill the rest of the last line with dots up to the page number.
+		cellMargin := pdf.GetCellMargin()
+		textWidth := pdf.GetStringWidth(line) + 2*cellMargin
+		leader := ""
+		for pdf.GetStringWidth(leader+" .") < w-captionListPageWidth-textWidth-2*cellMargin {
+			leader += " ."
+		}
+		pdf.CellFormat(textWidth, captionListLineHeight, line, "", 0, "L", false, 0, "")
+		pdf.CellFormat(w-captionListPageWidth-textWidth, captionListLineHeight, leader, "", 0, "R", false, 0, "")
+		pdf.CellFormat(captionListPageWidth, captionListLineHeight, strconv.Itoa(e.page), "", 1, "R", false, 0, "")
+	}
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ChartValue is a labelled value of a pie chart.
+type ChartValue struct {
+	Label string
+	Value float64
+}
+
+// ChartSeries is a labelled series of a bar chart, one value per category.
+type ChartSeries struct {
+	Label  string
+	Values []float64
+}
+
+// PieChart is a pie chart drawn with vector paths, with a legend of the
+// values and their shares on the right.
+type PieChart struct {
+	Values []ChartValue
+	// Hole is the radius of the hole as a fraction of the chart radius;
+	// a nonzero hole draws a donut with the total in the middle.
+	Hole float64
+	// Height is the diameter of the chart; zero means 70.
+	Height float64
+	Unit   string
+	Figure *Caption
+}
+
+// BarChart is a bar chart of Categories. Several series are stacked, with a
+// legend below the chart. Horizontal charts draw one bar per row with the
+// category on the left; vertical ones stand the bars on a base line with
+// the categories below.
+type BarChart struct {
+	Categories []string
+	Series     []ChartSeries
+	Horizontal bool
+	// Grouped draws the series of a vertical chart side by side instead of
+	// stacked.
+	Grouped bool
+	// Height is the height of the bars of a vertical chart; zero means 70.
+	Height float64
+	Unit   string
+	Figure *Caption
+}
+
+// chartColors are the fill colours of the slices and series, in order.
+var chartColors = [][3]int{
+	{79, 129, 189},
+	{192, 80, 77},
+	{155, 187, 89},
+	{128, 100, 162},
+	{75, 172, 198},
+	{247, 150, 70},
+	{44, 77, 117},
+	{119, 44, 42},
+}
+
+const (
+	chartFontSize        = 12.0
+	chartLabelFontSize   = 10.0
+	chartLineHeight      = 6.0
+	chartLegendBox       = 4.0
+	chartGap             = 3.0
+	chartBarHeight       = 8.0
+	chartValueWidth      = 35.0
+	chartMaxLabelShare   = 0.4
+	chartMinSliceLabel   = 0.05
+	chartArcStepDegrees  = 2.0
+	chartDefaultDiameter = 70.0
+)
+
+func chartColor(i int) [3]int {
+	return chartColors[i%len(chartColors)]
+}
+
+// drawChartText prints text centred on x, y in the chart label font.
+func drawChartText(pdf *gofpdf.Fpdf, x, y float64, text string) {
+	w := pdf.GetStringWidth(text) + 2*pdf.GetCellMargin()
+	pdf.SetXY(x-w/2, y-chartLineHeight/2)
+	pdf.CellFormat(w, chartLineHeight, text, "", 0, "C", false, 0, "")
+}
+
+// drawLegend draws a colour box and label per entry from x, y down and
+// returns the y below the legend.
+func drawLegend(pdf *gofpdf.Fpdf, x, y, w float64, labels []string) float64 {
+	pdf.SetFont("THSarabunNew", "", chartFontSize)
+	for i, label := range labels {
+		color := chartColor(i)
+		pdf.SetFillColor(color[0], color[1], color[2])
+		pdf.Rect(x, y+(chartLineHeight-chartLegendBox)/2, chartLegendBox, chartLegendBox, "F")
+		pdf.SetXY(x+chartLegendBox+1, y)
+		pdf.CellFormat(w-chartLegendBox-1, chartLineHeight, label, "", 0, "L", false, 0, "")
+		y += chartLineHeight
+	}
+	return y
+}
+
+// arcPoints returns points on a circle around cx, cy from angle start to
+// end, in degrees clockwise from the top.
+func arcPoints(cx, cy, r, start, end float64) []gofpdf.PointType {
+	steps := int(math.Ceil((end-start)/chartArcStepDegrees)) + 1
+	points := make([]gofpdf.PointType, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		a := (start + (end-start)*float64(i)/float64(steps) - 90) * math.Pi / 180
+		points = append(points, gofpdf.PointType{X: cx + r*math.Cos(a), Y: cy + r*math.Sin(a)})
+	}
+	return points
+}
+
+func (c PieChart) diameter() float64 {
+	if c.Height == 0 {
+		return chartDefaultDiameter
+	}
+	return c.Height
+}
+
+func (c PieChart) total() float64 {
+	total := 0.0
+	for _, v := range c.Values {
+		total += v.Value
+	}
+	return total
+}
+
+func (c PieChart) legend() []string {
+	total := c.total()
+	labels := make([]string, len(c.Values))
+	for i, v := range c.Values {
+		share := "-"
+		if total > 0 {
+			share = formatNumber(100*v.Value/total, 1) + "%"
+		}
+		labels[i] = v.Label + "  " + formatNumber(v.Value, 2) + " " + c.Unit + " (" + share + ")"
+	}
+	return labels
+}
+
+func (c PieChart) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	h := math.Max(c.diameter(), chartLineHeight*float64(len(c.Values)))
+	if c.Figure != nil {
+		h += captionGap + c.Figure.height(pdf, w)
+	}
+	return h
+}
+
+func (c PieChart) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return c.height(pdf, w)
+}
+
+func (c PieChart) render(pdf *gofpdf.Fpdf, w float64) {
+	left, _, _, _ := pdf.GetMargins()
+	y := pdf.GetY()
+	if c.Figure != nil {
+		c.Figure.place(pdf)
+	}
+
+	r := c.diameter() / 2
+	cx, cy := left+r, y+r
+	total := c.total()
+
+	drawR, drawG, drawB := pdf.GetDrawColor()
+	lineWidth := pdf.GetLineWidth()
+	pdf.SetDrawColor(255, 255, 255)
+	pdf.SetLineWidth(0.5)
+
+	pdf.SetFont("THSarabunNew", "", chartLabelFontSize)
+	start := 0.0
+	for i, v := range c.Values {
+		if v.Value <= 0 {
+			continue
+		}
+		end := start + 360*v.Value/total
+		var points []gofpdf.PointType
+		if c.Hole > 0 {
+			inner := arcPoints(cx, cy, r*c.Hole, start, end)
+			points = arcPoints(cx, cy, r, start, end)
+			for j := len(inner) - 1; j >= 0; j-- {
+				points = append(points, inner[j])
+			}
+		} else {
+			points = append([]gofpdf.PointType{{X: cx, Y: cy}}, arcPoints(cx, cy, r, start, end)...)
+		}
+		color := chartColor(i)
+		pdf.SetFillColor(color[0], color[1], color[2])
+		pdf.Polygon(points, "FD")
+
+		if share := v.Value / total; share >= chartMinSliceLabel {
+			labelR := r * 0.65
+			if c.Hole > 0 {
+				labelR = r * (1 + c.Hole) / 2
+			}
+			mid := arcPoints(cx, cy, labelR, (start+end)/2, (start+end)/2)[0]
+			pdf.SetTextColor(255, 255, 255)
+			drawChartText(pdf, mid.X, mid.Y, formatNumber(100*share, 1)+"%")
+			pdf.SetTextColor(0, 0, 0)
+		}
+		start = end
+	}
+	pdf.SetDrawColor(drawR, drawG, drawB)
+	pdf.SetLineWidth(lineWidth)
+
+	if c.Hole > 0 {
+		pdf.SetFont("THSarabunNew", "B", chartLabelFontSize)
+		drawChartText(pdf, cx, cy-chartLineHeight/2, "รวม")
+		drawChartText(pdf, cx, cy+chartLineHeight/2, formatNumber(total, 2))
+	}
+
+	legendX := left + 2*r + 10
+	legendHeight := chartLineHeight * float64(len(c.Values))
+	drawLegend(pdf, legendX, y+math.Max(0, c.diameter()-legendHeight)/2, left+w-legendX, c.legend())
+
+	y += math.Max(c.diameter(), legendHeight)
+	if c.Figure != nil {
+		pdf.SetXY(left, y+captionGap)
+		c.Figure.render(pdf, w)
+		y = pdf.GetY()
+	}
+	pdf.SetXY(left, y)
+}
+
+func (c BarChart) barHeight() float64 {
+	if c.Height == 0 {
+		return chartDefaultDiameter
+	}
+	return c.Height
+}
+
+// totals returns the sum of the series for each category and the largest.
+func (c BarChart) totals() (totals []float64, largest float64) {
+	totals = make([]float64, len(c.Categories))
+	for _, series := range c.Series {
+		for i := range totals {
+			if i < len(series.Values) {
+				totals[i] += series.Values[i]
+			}
+		}
+	}
+	for _, total := range totals {
+		largest = math.Max(largest, total)
+	}
+	if c.grouped() {
+		largest = 0
+		for _, series := range c.Series {
+			for _, v := range series.Values {
+				largest = math.Max(largest, v)
+			}
+		}
+	}
+	return totals, largest
+}
+
+func (c BarChart) grouped() bool {
+	return c.Grouped && !c.Horizontal
+}
+
+func (c BarChart) legend() []string {
+	if len(c.Series) < 2 {
+		return nil
+	}
+	labels := make([]string, len(c.Series))
+	for i, series := range c.Series {
+		labels[i] = series.Label
+	}
+	return labels
+}
+
+// labelWidth returns the width of the category column of a horizontal
+// chart at width w.
+func (c BarChart) labelWidth(pdf *gofpdf.Fpdf, w float64) float64 {
+	pdf.SetFont("THSarabunNew", "", chartFontSize)
+	width := 0.0
+	for _, category := range c.Categories {
+		width = math.Max(width, pdf.GetStringWidth(category)+2*pdf.GetCellMargin())
+	}
+	return math.Min(width, w*chartMaxLabelShare)
+}
+
+// categoryLines returns the wrapped label of each category and the height
+// of its row or label area.
+func (c BarChart) categoryLines(pdf *gofpdf.Fpdf, w float64) (lines [][]string, heights []float64) {
+	labelWidth := c.labelWidth(pdf, w)
+	if !c.Horizontal {
+		labelWidth = w / float64(max(1, len(c.Categories)))
+		pdf.SetFont("THSarabunNew", "", chartLabelFontSize)
+	}
+	for _, category := range c.Categories {
+		wrapped := splitLines(pdf, category, labelWidth)
+		lines = append(lines, wrapped)
+		h := chartLineHeight * float64(len(wrapped))
+		if c.Horizontal {
+			h = math.Max(h, chartBarHeight) + chartGap
+		}
+		heights = append(heights, h)
+	}
+	return lines, heights
+}
+
+func (c BarChart) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	_, heights := c.categoryLines(pdf, w)
+	h := 0.0
+	if c.Horizontal {
+		for _, height := range heights {
+			h += height
+		}
+	} else {
+		labels := 0.0
+		for _, height := range heights {
+			labels = math.Max(labels, height)
+		}
+		h = chartLineHeight + c.barHeight() + labels
+	}
+	if legend := c.legend(); len(legend) > 0 {
+		h += chartGap + chartLineHeight*float64(len(legend))
+	}
+	if c.Figure != nil {
+		h += captionGap + c.Figure.height(pdf, w)
+	}
+	return h
+}
+
+func (c BarChart) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return c.height(pdf, w)
+}
+
+func (c BarChart) render(pdf *gofpdf.Fpdf, w float64) {
+	left, _, _, _ := pdf.GetMargins()
+	y := pdf.GetY()
+	if c.Figure != nil {
+		c.Figure.place(pdf)
+	}
+
+	if c.Horizontal {
+		y = c.renderHorizontal(pdf, left, y, w)
+	} else {
+		y = c.renderVertical(pdf, left, y, w)
+	}
+
+	if legend := c.legend(); len(legend) > 0 {
+		y = drawLegend(pdf, left, y+chartGap, w, legend)
+	}
+	if c.Figure != nil {
+		pdf.SetXY(left, y+captionGap)
+		c.Figure.render(pdf, w)
+		y = pdf.GetY()
+	}
+	pdf.SetXY(left, y)
+}
+
+// renderHorizontal draws a bar per row with the category on the left and
+// the total after the bar, and returns the y below the chart.
+func (c BarChart) renderHorizontal(pdf *gofpdf.Fpdf, x, y, w float64) float64 {
+	labelWidth := c.labelWidth(pdf, w)
+	lines, heights := c.categoryLines(pdf, w)
+	totals, largest := c.totals()
+	plotX := x + labelWidth
+	plotWidth := w - labelWidth - chartValueWidth
+
+	for i := range c.Categories {
+		rowHeight := heights[i] - chartGap
+		pdf.SetFont("THSarabunNew", "", chartFontSize)
+		drawLines(pdf, x, y+(rowHeight-chartLineHeight*float64(len(lines[i])))/2, labelWidth, chartLineHeight, lines[i])
+
+		barX := plotX
+		barY := y + (rowHeight-chartBarHeight)/2
+		for j, series := range c.Series {
+			if i >= len(series.Values) || largest == 0 {
+				continue
+			}
+			barWidth := plotWidth * series.Values[i] / largest
+			color := chartColor(j)
+			if len(c.Series) == 1 {
+				color = chartColor(i)
+			}
+			pdf.SetFillColor(color[0], color[1], color[2])
+			pdf.Rect(barX, barY, barWidth, chartBarHeight, "F")
+			barX += barWidth
+		}
+
+		pdf.SetFont("THSarabunNew", "", chartLabelFontSize)
+		pdf.SetXY(barX, barY+(chartBarHeight-chartLineHeight)/2)
+		pdf.CellFormat(chartValueWidth, chartLineHeight, formatNumber(totals[i], 2)+" "+c.Unit, "", 0, "L", false, 0, "")
+		y += heights[i]
+	}
+	pdf.Line(plotX, y-sum(heights), plotX, y-chartGap)
+	return y
+}
+
+// renderVertical draws the bars on a base line with the totals above them
+// and the categories below, and returns the y below the chart.
+func (c BarChart) renderVertical(pdf *gofpdf.Fpdf, x, y, w float64) float64 {
+	lines, heights := c.categoryLines(pdf, w)
+	totals, largest := c.totals()
+	slot := w / float64(max(1, len(c.Categories)))
+	barWidth := slot * 0.5
+	base := y + chartLineHeight + c.barHeight()
+
+	if c.grouped() {
+		barWidth = slot * 0.8 / float64(max(1, len(c.Series)))
+	}
+
+	for i := range c.Categories {
+		if c.grouped() {
+			c.renderGroup(pdf, x+slot*float64(i)+slot*0.1, base, barWidth, largest, i)
+		}
+		barX := x + slot*float64(i) + (slot-barWidth)/2
+		top := base
+		for j, series := range c.Series {
+			if c.grouped() {
+				break
+			}
+			if i >= len(series.Values) || largest == 0 {
+				continue
+			}
+			barHeight := c.barHeight() * series.Values[i] / largest
+			color := chartColor(j)
+			if len(c.Series) == 1 {
+				color = chartColor(i)
+			}
+			pdf.SetFillColor(color[0], color[1], color[2])
+			pdf.Rect(barX, top-barHeight, barWidth, barHeight, "F")
+			top -= barHeight
+		}
+
+		pdf.SetFont("THSarabunNew", "", chartLabelFontSize)
+		if !c.grouped() {
+			drawChartText(pdf, barX+barWidth/2, top-chartLineHeight/2, formatNumber(totals[i], 2)+" "+c.Unit)
+		}
+		for j, line := range lines[i] {
+			pdf.SetXY(x+slot*float64(i), base+chartLineHeight*float64(j))
+			pdf.CellFormat(slot, chartLineHeight, line, "", 0, "C", false, 0, "")
+		}
+	}
+	pdf.Line(x, base, x+w, base)
+
+	labels := 0.0
+	for _, height := range heights {
+		labels = math.Max(labels, height)
+	}
+	return base + labels
+}
+
+// renderGroup draws the bars of category i side by side from x, each with
+// its value above it.
+func (c BarChart) renderGroup(pdf *gofpdf.Fpdf, x, base, barWidth, largest float64, i int) {
+	pdf.SetFont("THSarabunNew", "", chartLabelFontSize)
+	for j, series := range c.Series {
+		// Missing values are left out rather than drawn as 0.
+		if i >= len(series.Values) || series.Values[i] <= 0 || largest == 0 {
+			continue
+		}
+		barX := x + barWidth*float64(j)
+		barHeight := c.barHeight() * series.Values[i] / largest
+		color := chartColor(j)
+		pdf.SetFillColor(color[0], color[1], color[2])
+		pdf.Rect(barX, base-barHeight, barWidth, barHeight, "F")
+		drawChartText(pdf, barX+barWidth/2, base-barHeight-chartLineHeight/2, formatNumber(series.Values[i], 2))
+	}
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,127 @@
+package main
+
+import "strconv"
+
+// CreditKind is what a credit of section 3.2.8 certifies.
+type CreditKind int
+
+const (
+	ReductionProject     CreditKind = iota // a reduction project of the organisation, in tCO2e
+	CarbonCredit                           // carbon credits bought from others, in tCO2e
+	RenewableCertificate                   // renewable energy certificates (RECs), in kWh
+)
+
+var creditKindNames = []string{
+	ReductionProject:     "โครงการลดก๊าซเรือนกระจกขององค์กร",
+	CarbonCredit:         "คาร์บอนเครดิต",
+	RenewableCertificate: "สิทธิพลังงานหมุนเวียน REC",
+}
+
+func (k CreditKind) String() string {
+	if k < ReductionProject || k > RenewableCertificate {
+		return "CreditKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return creditKindNames[k]
+}
+
+// Unit returns the unit credits of the kind are counted in.
+func (k CreditKind) Unit() string {
+	if k == RenewableCertificate {
+		return "kWh"
+	}
+	return "tCO2e"
+}
+
+// Credit is a reduction project, carbon credit or renewable energy
+// certificate of section 3.2.8, certified under Standard, e.g. "T-VER" or
+// "I-REC", for the crediting Period. Sold is what was sold to others and
+// Retired what the organisation retired for its own claims, both in the
+// unit of the kind.
+//
+// Sold credits do not change the inventory. Retired RECs lower the
+// market-based scope 2 emissions by the electricity they cover, see
+// marketBased, and retired carbon credits offset emissions: they are shown
+// against the scope 1 and 2 total, which is still reported in full.
+type Credit struct {
+	Name     string
+	Kind     CreditKind
+	Standard string
+	Period   string
+	Sold     float64
+	Retired  float64
+}
+
+// retiredCertificates returns the electricity covered by retired RECs in
+// kWh.
+func (r Report) retiredCertificates() float64 {
+	kWh := 0.0
+	for _, c := range r.Credits {
+		if c.Kind == RenewableCertificate {
+			kWh += c.Retired
+		}
+	}
+	return kWh
+}
+
+// offsets returns the retired carbon credits in tCO2e.
+func (r Report) offsets() float64 {
+	total := 0.0
+	for _, c := range r.Credits {
+		if c.Kind != RenewableCertificate {
+			total += c.Retired
+		}
+	}
+	return total
+}
+
+// creditTable returns the table of section 3.2.8.
+func (r Report) creditTable() [][]string {
+	data := [][]string{{
+		"ชื่อโครงการ",
+		"มาตรฐานที่ขอรับรอง",
+		"ระยะเวลาคิดคาร์บอนเครดิตของโครงการ",
+		"จำนวนที่ได้รับการรับรองที่ขายไป",
+		"จำนวนที่ใช้ชดเชย (Retired)",
+		"หน่วย",
+	}}
+	value := func(v float64) string {
+		if v == 0 {
+			return "-"
+		}
+		return formatNumber(v, 2)
+	}
+	for _, c := range r.Credits {
+		data = append(data, []string{c.Name + "\n(" + c.Kind.String() + ")", c.Standard, c.Period, value(c.Sold), value(c.Retired), c.Kind.Unit()})
+	}
+	if len(r.Credits) == 0 {
+		data = append(data, []string{"-ไม่มี-", "", "", "", "", ""})
+	}
+	return data
+}
+
+// creditEffectTable returns the effect of the retired credits on the
+// totals: the market-based scope 2 emissions after RECs and the scope 1 and
+// 2 emissions net of carbon credits.
+func (r Report) creditEffectTable() [][]string {
+	scope2 := r.scope2(true)
+	_, certificates := r.marketBased()
+	total := r.inventory().ScopeTotal(1) + scope2
+	offsets := r.offsets()
+	return [][]string{
+		{"รายการ", "ปริมาณ (Ton CO2e)"},
+		{"ปริมาณการปล่อยก๊าซเรือนกระจกประเภทที่ 2 (Market-based) ก่อนใช้ REC", formatNumber(scope2+certificates, 2)},
+		{"หัก สิทธิพลังงานหมุนเวียนที่ใช้ชดเชย (REC)", formatNumber(-certificates, 2)},
+		{"ปริมาณการปล่อยก๊าซเรือนกระจกประเภทที่ 2 (Market-based)", formatNumber(scope2, 2)},
+		{"ปริมาณการปล่อยก๊าซเรือนกระจกประเภทที่ 1+2 (Market-based)", formatNumber(total, 2)},
+		{"หัก คาร์บอนเครดิตที่ใช้ชดเชย", formatNumber(-offsets, 2)},
+		{"ปริมาณการปล่อยก๊าซเรือนกระจกประเภทที่ 1+2 สุทธิหลังการชดเชย", formatNumber(total-offsets, 2)},
+	}
+}
+
+// creditNotes returns the notes under the table of section 3.2.8.
+func (r Report) creditNotes() *TableNotes {
+	notes := &TableNotes{Title: "หมายเหตุ"}
+	notes.Add("คาร์บอนเครดิตและสิทธิพลังงานหมุนเวียนที่ขายไปไม่นำมาหักออกจากปริมาณการปล่อยก๊าซเรือนกระจกขององค์กร")
+	notes.Add("สิทธิพลังงานหมุนเวียนที่ใช้ชดเชยหักจากไฟฟ้าที่ไม่มีค่า EF ของผู้จำหน่าย ที่ค่า EF " + formatFactor(r.residualFactor()) + " kgCO2e/kWh ตามข้อ 5.2")
+	return notes
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Consolidation is the approach used to set the organisational boundary in
+// section 3.1: which facilities are counted, and how much of each.
+type Consolidation int
+
+const (
+	OperationalControl Consolidation = iota
+	FinancialControl
+	EquityShare
+)
+
+var consolidationNames = []string{
+	OperationalControl: "ควบคุมดำเนินงาน (OPERATIONAL CONTROL)",
+	FinancialControl:   "ควบคุมทางการเงิน (FINANCIAL CONTROL)",
+	EquityShare:        "สัดส่วนการถือหุ้น (EQUITY SHARE)",
+}
+
+func (c Consolidation) String() string {
+	if c < OperationalControl || c > EquityShare {
+		return "Consolidation(" + strconv.Itoa(int(c)) + ")"
+	}
+	return consolidationNames[c]
+}
+
+// Facility is a site covered by the report, e.g. "LR1", with what the
+// organisation holds of it under each consolidation approach.
+type Facility struct {
+	Code               string
+	Name               string
+	OperationalControl bool
+	FinancialControl   bool
+	EquityShare        float64 // as a fraction, e.g. 0.6
+}
+
+// share returns the fraction of the emissions of f that is consolidated
+// under c.
+func (f Facility) share(c Consolidation) float64 {
+	switch c {
+	case FinancialControl:
+		if f.FinancialControl {
+			return 1
+		}
+	case EquityShare:
+		return f.EquityShare
+	default:
+		if f.OperationalControl {
+			return 1
+		}
+	}
+	return 0
+}
+
+// ActivityRecord is the emissions of a source at a facility in tCO2e, before
+// consolidation. Facility is empty for data recorded for all facilities
+// together, which cannot be broken down.
+type ActivityRecord struct {
+	Facility  string
+	Scope     int
+	Source    string
+	Emissions float64
+}
+
+// records returns the activity records of the inventory: the scope 1
+// emissions of the fuel records and the location-based scope 2 emissions of
+// the energy purchases, followed by the records of totals.
+func (r Report) records() []ActivityRecord {
+	var records []ActivityRecord
+	for _, record := range r.FuelRecords {
+		records = append(records, ActivityRecord{
+			Facility:  record.Facility,
+			Scope:     1,
+			Source:    record.Source,
+			Emissions: r.scopeEmissions(record),
+		})
+	}
+	for _, p := range r.Energy {
+		records = append(records, ActivityRecord{
+			Facility:  p.Facility,
+			Scope:     2,
+			Source:    p.Source,
+			Emissions: r.locationBased(p),
+		})
+	}
+	return append(records, r.Records...)
+}
+
+// facility returns the facility with code.
+func (r Report) facility(code string) (Facility, bool) {
+	for _, f := range r.Facilities {
+		if f.Code == code {
+			return f, true
+		}
+	}
+	return Facility{}, false
+}
+
+// facilityName returns the numbered name of the facility with code as
+// listed in section 3.1, e.g. "1. ... โรงงานลพบุรี 1 (LR1)", or code when
+// it is unknown.
+func (r Report) facilityName(code string) string {
+	for i, f := range r.Facilities {
+		if f.Code == code {
+			return strconv.Itoa(i+1) + ". " + f.Name + " (" + f.Code + ")"
+		}
+	}
+	return code
+}
+
+// facilityList returns the numbered names of the facilities, one per line.
+func (r Report) facilityList() string {
+	var lines []string
+	for _, f := range r.Facilities {
+		lines = append(lines, r.facilityName(f.Code))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// consolidationShare returns the share of the emissions recorded for
+// facility code that is consolidated. Records for all facilities together
+// can only be consolidated when every facility has the same share; false
+// is returned when they do not or the facility is unknown.
+func (r Report) consolidationShare(code string) (float64, bool) {
+	if code != "" {
+		f, ok := r.facility(code)
+		return f.share(r.Consolidation), ok
+	}
+	if len(r.Facilities) == 0 {
+		return 0, false
+	}
+	share := r.Facilities[0].share(r.Consolidation)
+	for _, f := range r.Facilities[1:] {
+		if f.share(r.Consolidation) != share {
+			return 0, false
+		}
+	}
+	return share, true
+}
+
+// inventory returns the consolidated inventory of the report year, with
+// scope 2 counted location-based. The materiality of section 3.2, the
+// carbon intensities of section 5.5 and the comparison with the base year in
+// section 6 are all taken from it.
+func (r Report) inventory() Inventory {
+	return r.facilityInventory(nil)
+}
+
+// facilityInventory returns the consolidated inventory of the records
+// selected by keep, or of all records when keep is nil. Sources are in
+// the order they are first recorded.
+func (r Report) facilityInventory(keep func(ActivityRecord) bool) Inventory {
+	inv := Inventory{Year: r.Year}
+	index := map[string]int{}
+	for _, record := range r.records() {
+		key := strconv.Itoa(record.Scope) + "/" + record.Source
+		i, ok := index[key]
+		if !ok {
+			i = len(inv.Sources)
+			index[key] = i
+			inv.Sources = append(inv.Sources, EmissionSource{Scope: record.Scope, Name: record.Source})
+		}
+		if keep != nil && !keep(record) {
+			continue
+		}
+		share, _ := r.consolidationShare(record.Facility)
+		inv.Sources[i].Emissions += share * record.Emissions
+	}
+	return inv
+}
+
+// facilityLabel returns the codes of all facilities joined like the
+// merged labels of the report, e.g. "LR1,2,3".
+func (r Report) facilityLabel() string {
+	var b strings.Builder
+	prefix := ""
+	for i, f := range r.Facilities {
+		code := f.Code
+		if i == 0 {
+			prefix = strings.TrimRight(code, "0123456789")
+		} else {
+			b.WriteString(",")
+			if prefix != "" && strings.HasPrefix(code, prefix) {
+				code = strings.TrimPrefix(code, prefix)
+			}
+		}
+		b.WriteString(code)
+	}
+	return b.String()
+}
+
+// facilityColumn is the inventory of a facility, or of the data recorded
+// for all facilities together, under its label.
+type facilityColumn struct {
+	label string
+	inv   Inventory
+}
+
+// facilityColumns returns the consolidated inventory of each facility, and
+// of the data recorded for all facilities together when there is any.
+func (r Report) facilityColumns() []facilityColumn {
+	var columns []facilityColumn
+	for _, f := range r.Facilities {
+		code := f.Code
+		columns = append(columns, facilityColumn{code, r.facilityInventory(func(record ActivityRecord) bool {
+			return record.Facility == code
+		})})
+	}
+	for _, record := range r.records() {
+		if record.Facility == "" {
+			columns = append(columns, facilityColumn{r.facilityLabel() + " (ไม่แยก Facility)", r.facilityInventory(func(record ActivityRecord) bool {
+				return record.Facility == ""
+			})})
+			break
+		}
+	}
+	return columns
+}
+
+// facilityTable returns the consolidated emissions of each scope and source
+// broken down by facility, with a column for the data recorded for all
+// facilities together when there is any, and the consolidated total.
+func (r Report) facilityTable() [][]string {
+	columns := r.facilityColumns()
+	columns = append(columns, facilityColumn{"รวม (Ton CO2e)", r.inventory()})
+
+	header := []string{"ขอบเขต / แหล่งปล่อยก๊าซเรือนกระจก"}
+	for _, c := range columns {
+		header = append(header, c.label)
+	}
+	data := [][]string{header}
+	value := func(v float64) string {
+		if v == 0 {
+			return "-"
+		}
+		return formatNumber(v, 2)
+	}
+	total := columns[len(columns)-1].inv
+	for scope := 1; scope <= 3; scope++ {
+		if !total.hasScope(scope) {
+			continue
+		}
+		row := []string{scopeName(scope)}
+		for _, c := range columns {
+			row = append(row, value(c.inv.ScopeTotal(scope)))
+		}
+		data = append(data, row)
+		for _, source := range total.Sources {
+			if source.Scope != scope {
+				continue
+			}
+			row := []string{"   - " + source.Name}
+			for _, c := range columns {
+				s, _ := c.inv.source(scope, source.Name)
+				row = append(row, value(s.Emissions))
+			}
+			data = append(data, row)
+		}
+	}
+	row := []string{"รวมทั้งหมด"}
+	for _, c := range columns {
+		row = append(row, value(c.inv.ScopeTotal(0)))
+	}
+	return append(data, row)
+}
+
+// facilityChart returns a horizontal bar chart of the consolidated
+// emissions of each facility of facilityTable, stacked by scope.
+func (r Report) facilityChart() BarChart {
+	chart := BarChart{Horizontal: true, Unit: "tCO2e"}
+	columns := r.facilityColumns()
+	for _, c := range columns {
+		chart.Categories = append(chart.Categories, c.label)
+	}
+	for scope := 1; scope <= 3; scope++ {
+		series := ChartSeries{Label: scopeName(scope)}
+		for _, c := range columns {
+			series.Values = append(series.Values, c.inv.ScopeTotal(scope))
+		}
+		chart.Series = append(chart.Series, series)
+	}
+	return chart
+}
+
+// facilityTableWidth returns the column widths of facilityTable for n
+// columns in 170 mm.
+func facilityTableWidth(n int) []float64 {
+	width := []float64{50.0}
+	for i := 1; i < n; i++ {
+		width = append(width, 120.0/float64(n-1))
+	}
+	return width
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Block is a piece of content placed by generateFlowContent.
+type Block interface {
+	// height returns the height of the whole block at width w.
+	height(pdf *gofpdf.Fpdf, w float64) float64
+	// leadHeight returns the least height that has to fit on the current
+	// page for the block to start there.
+	leadHeight(pdf *gofpdf.Fpdf, w float64) float64
+	render(pdf *gofpdf.Fpdf, w float64)
+}
+
+// Heading is a section title. A heading is never left alone at the bottom
+// of a page: it moves to the next page with the start of the block after it.
+type Heading struct {
+	Text string
+	// Size is the font size; zero means 16.
+	Size float64
+	// Align is "L", "C" or "R" as in MultiCell; empty means "L".
+	Align string
+}
+
+// Spacer is vertical space. Like a heading, it stays with the next block.
+type Spacer struct {
+	Height float64
+}
+
+// PageBreak starts a new page.
+type PageBreak struct{}
+
+// Paragraph is body text. A paragraph split over two pages keeps at least
+// Orphans lines at the bottom of the first page and Widows lines at the top
+// of the next one.
+type Paragraph struct {
+	Text string
+	// Indent tabs the first line like generateTextContent.
+	Indent bool
+	// Orphans and Widows default to 2 when zero.
+	Orphans int
+	Widows  int
+}
+
+// Table is a table drawn with generateNotedTableContent. A KeepTogether
+// table starts on a new page rather than being split, unless it is taller
+// than a page.
+type Table struct {
+	Data         [][]string
+	Width        []float64
+	Notes        *TableNotes
+	KeepTogether bool
+}
+
+const (
+	headingLineHeight   = 10.0
+	paragraphLineHeight = 10.0
+	paragraphIndent     = "           " // This is equivalent to a tab
+)
+
+// headerHeight is the space generateHeader takes below the top margin and
+// footerHeight the space kept free for generateFooter at the bottom of the
+// page, which is also the auto page break margin.
+const (
+	headerHeight = 50.0
+	footerHeight = 40.0
+)
+
+// contentBounds returns the top and bottom of the area between the page
+// header and footer, taken from the margins of pdf.
+func contentBounds(pdf *gofpdf.Fpdf) (top, bottom float64) {
+	_, pageHeight := pdf.GetPageSize()
+	_, topMargin, _, _ := pdf.GetMargins()
+	_, breakMargin := pdf.GetAutoPageBreak()
+	return topMargin + headerHeight, pageHeight - breakMargin
+}
+
+// contentWidth returns the width between the left and right margins.
+func contentWidth(pdf *gofpdf.Fpdf) float64 {
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	return pageWidth - left - right
+}
+
+// generateFlowContent draws blocks one below the other from the current
+// position, starting new pages where a block or a heading and its next
+// block would not fit.
+func generateFlowContent(pdf *gofpdf.Fpdf, blocks ...Block) {
+	top, bottom := contentBounds(pdf)
+	w := contentWidth(pdf)
+
+	for i, block := range blocks {
+		need := block.leadHeight(pdf, w)
+		for j := i; j+1 < len(blocks) && keepsWithNext(blocks[j]); j++ {
+			need += blocks[j+1].leadHeight(pdf, w)
+		}
+
+		// Blocks taller than a page are split wherever the page ends.
+		if pdf.GetY()+need > bottom && top+need <= bottom {
+			pdf.AddPage()
+			pdf.SetY(top)
+		}
+		block.render(pdf, w)
+	}
+}
+
+// keepsWithNext reports whether block has to start on the same page as the
+// block after it: headings, spacers and the captions of tables, which would
+// otherwise be left at the bottom of a page apart from their table.
+func keepsWithNext(block Block) bool {
+	switch block.(type) {
+	case Heading, Spacer, *Caption:
+		return true
+	}
+	return false
+}
+
+func (h Heading) size() float64 {
+	if h.Size == 0 {
+		return 16
+	}
+	return h.Size
+}
+
+func (h Heading) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	pdf.SetFont("THSarabunNew", "B", h.size())
+	return headingLineHeight * float64(len(splitLines(pdf, h.Text, w)))
+}
+
+func (h Heading) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return h.height(pdf, w)
+}
+
+func (h Heading) render(pdf *gofpdf.Fpdf, w float64) {
+	pdf.SetFont("THSarabunNew", "B", h.size())
+	align := h.Align
+	if align == "" {
+		align = "L"
+	}
+	pdf.MultiCell(w, headingLineHeight, h.Text, "", align, false)
+}
+
+func (s Spacer) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	return s.Height
+}
+
+func (s Spacer) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return s.Height
+}
+
+func (s Spacer) render(pdf *gofpdf.Fpdf, w float64) {
+	pdf.Ln(s.Height)
+}
+
+func (PageBreak) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	return 0
+}
+
+func (PageBreak) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return 0
+}
+
+func (PageBreak) render(pdf *gofpdf.Fpdf, w float64) {
+	top, _ := contentBounds(pdf)
+	pdf.AddPage()
+	pdf.SetY(top)
+}
+
+func (p Paragraph) lines(pdf *gofpdf.Fpdf, w float64) []string {
+	pdf.SetFont("THSarabunNew", "", 14)
+	text := p.Text
+	if p.Indent {
+		text = paragraphIndent + text
+	}
+	return splitLines(pdf, text, w)
+}
+
+func (p Paragraph) orphans() int {
+	if p.Orphans == 0 {
+		return 2
+	}
+	return p.Orphans
+}
+
+func (p Paragraph) widows() int {
+	if p.Widows == 0 {
+		return 2
+	}
+	return p.Widows
+}
+
+func (p Paragraph) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	return paragraphLineHeight * float64(len(p.lines(pdf, w)))
+}
+
+func (p Paragraph) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	n := len(p.lines(pdf, w))
+	if n < p.orphans()+p.widows() {
+		// Too short to split without leaving a widow or an orphan.
+		return paragraphLineHeight * float64(n)
+	}
+	return paragraphLineHeight * float64(p.orphans())
+}
+
+func (p Paragraph) render(pdf *gofpdf.Fpdf, w float64) {
+	top, bottom := contentBounds(pdf)
+	left, _, _, _ := pdf.GetMargins()
+
+	lines := p.lines(pdf, w)
+	for len(lines) > 0 {
+		fit := int(math.Floor((bottom - pdf.GetY()) / paragraphLineHeight))
+		n := len(lines)
+		if fit < n {
+			n = min(fit, n-p.widows())
+			if n < p.orphans() {
+				n = 0
+			}
+		}
+		// Orphans and widows that do not fit on a whole page are ignored
+		// there, or the paragraph would never start.
+		if n == 0 && pdf.GetY() <= top {
+			n = max(1, min(fit, len(lines)))
+		}
+		for _, line := range lines[:n] {
+			pdf.SetX(left)
+			pdf.CellFormat(w, paragraphLineHeight, line, "", 1, "L", false, 0, "")
+		}
+		lines = lines[n:]
+		if len(lines) > 0 {
+			pdf.AddPage()
+			pdf.SetY(top)
+		}
+	}
+}
+
+func (t Table) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	h := t.Notes.height(pdf, w)
+	pdf.SetFont("THSarabunNew", "", 14)
+	for _, row := range t.Data {
+		lines, maxLineIndex := tableRowLines(pdf, row, t.Width)
+		h += 10.0 * lines[maxLineIndex]
+	}
+	return h
+}
+
+func (t Table) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	if t.KeepTogether || len(t.Data) < 2 {
+		return t.height(pdf, w)
+	}
+	pdf.SetFont("THSarabunNew", "", 14)
+	lines, maxLineIndex := tableRowLines(pdf, t.Data[0], t.Width)
+	return 10.0 * lines[maxLineIndex]
+}
+
+func (t Table) render(pdf *gofpdf.Fpdf, w float64) {
+	generateNotedTableContent(pdf, t.Data, t.Width, t.Notes)
+}
+
+func (l List) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	pdf.SetFont("THSarabunNew", "", 14)
+	return 10.0 * countListLines(pdf, l, w)
+}
+
+func (l List) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return math.Min(l.height(pdf, w), 2*10.0)
+}
+
+func (l List) render(pdf *gofpdf.Fpdf, w float64) {
+	generateListContent(pdf, l)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"math"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// FlowDiagram draws table data such as the section 7.2 data quality plans
+// as flows of boxes joined by arrows: the first row names the steps and
+// every other row is a flow through them. Flows run left to right, or top
+// to bottom with the step names on the left when Vertical is set.
+type FlowDiagram struct {
+	Data     [][]string
+	Vertical bool
+}
+
+const (
+	flowFontSize      = 12.0
+	flowLineHeight    = 6.0
+	flowBoxPadding    = 1.5
+	flowArrowLength   = 8.0
+	flowArrowHead     = 2.0
+	flowRowGap        = 6.0
+	flowStepNameWidth = 45.0
+	flowVerticalBox   = 90.0
+)
+
+func (d FlowDiagram) steps() []string {
+	if len(d.Data) == 0 {
+		return nil
+	}
+	return d.Data[0]
+}
+
+func (d FlowDiagram) flows() [][]string {
+	if len(d.Data) < 2 {
+		return nil
+	}
+	return d.Data[1:]
+}
+
+// boxWidth returns the width of the boxes at width w.
+func (d FlowDiagram) boxWidth(w float64) float64 {
+	if d.Vertical {
+		return math.Min(flowVerticalBox, w-flowStepNameWidth)
+	}
+	n := float64(max(1, len(d.steps())))
+	return (w - flowArrowLength*(n-1)) / n
+}
+
+// boxLines returns the wrapped text of each box of flow.
+func (d FlowDiagram) boxLines(pdf *gofpdf.Fpdf, flow []string, w float64) [][]string {
+	pdf.SetFont("THSarabunNew", "", flowFontSize)
+	lines := make([][]string, len(flow))
+	for i, text := range flow {
+		if strings.TrimSpace(text) == "" {
+			text = "-"
+		}
+		lines[i] = splitLines(pdf, text, d.boxWidth(w))
+	}
+	return lines
+}
+
+func boxHeight(lines []string) float64 {
+	return flowLineHeight*float64(len(lines)) + 2*flowBoxPadding
+}
+
+// titleHeight returns the height of the step names above horizontal flows.
+func (d FlowDiagram) titleHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	if d.Vertical {
+		return 0
+	}
+	pdf.SetFont("THSarabunNew", "B", flowFontSize)
+	h := 0.0
+	for _, step := range d.steps() {
+		h = math.Max(h, flowLineHeight*float64(len(splitLines(pdf, step, d.boxWidth(w)))))
+	}
+	return h
+}
+
+// flowHeight returns the height of one flow.
+func (d FlowDiagram) flowHeight(pdf *gofpdf.Fpdf, flow []string, w float64) float64 {
+	lines := d.boxLines(pdf, flow, w)
+	h := 0.0
+	for _, box := range lines {
+		if d.Vertical {
+			h += boxHeight(box)
+		} else {
+			h = math.Max(h, boxHeight(box))
+		}
+	}
+	if d.Vertical {
+		h += flowArrowLength * float64(max(0, len(lines)-1))
+	}
+	return h
+}
+
+func (d FlowDiagram) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	h := d.titleHeight(pdf, w)
+	for i, flow := range d.flows() {
+		if i > 0 {
+			h += flowRowGap
+		}
+		h += d.flowHeight(pdf, flow, w)
+	}
+	return h
+}
+
+func (d FlowDiagram) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	h := d.titleHeight(pdf, w)
+	if flows := d.flows(); len(flows) > 0 {
+		h += d.flowHeight(pdf, flows[0], w)
+	}
+	return h
+}
+
+func (d FlowDiagram) render(pdf *gofpdf.Fpdf, w float64) {
+	top, bottom := contentBounds(pdf)
+	left, _, _, _ := pdf.GetMargins()
+	boxWidth := d.boxWidth(w)
+
+	y := pdf.GetY()
+	titleHeight := d.titleHeight(pdf, w)
+	drawTitles := func() {
+		if d.Vertical {
+			return
+		}
+		pdf.SetFont("THSarabunNew", "B", flowFontSize)
+		for i, step := range d.steps() {
+			x := left + float64(i)*(boxWidth+flowArrowLength)
+			lines := splitLines(pdf, step, boxWidth)
+			for j, line := range lines {
+				pdf.SetXY(x, y+titleHeight-flowLineHeight*float64(len(lines)-j))
+				pdf.CellFormat(boxWidth, flowLineHeight, line, "", 0, "C", false, 0, "")
+			}
+		}
+		y += titleHeight
+	}
+	drawTitles()
+
+	for i, flow := range d.flows() {
+		if i > 0 {
+			y += flowRowGap
+		}
+		// Each flow is kept whole; the step names are repeated on the new
+		// page.
+		if h := d.flowHeight(pdf, flow, w); y+h > bottom && y > top {
+			pdf.AddPage()
+			y = top
+			drawTitles()
+		}
+		if d.Vertical {
+			y = d.renderVertical(pdf, left, y, w, flow)
+		} else {
+			y = d.renderHorizontal(pdf, left, y, w, flow)
+		}
+	}
+	pdf.SetXY(left, y)
+}
+
+// renderHorizontal draws flow as a row of boxes from x, y and returns the y
+// below it.
+func (d FlowDiagram) renderHorizontal(pdf *gofpdf.Fpdf, x, y, w float64, flow []string) float64 {
+	boxWidth := d.boxWidth(w)
+	lines := d.boxLines(pdf, flow, w)
+	h := d.flowHeight(pdf, flow, w)
+
+	for i, box := range lines {
+		boxX := x + float64(i)*(boxWidth+flowArrowLength)
+		drawFlowBox(pdf, boxX, y, boxWidth, h, box)
+		if i > 0 {
+			drawArrow(pdf, boxX-flowArrowLength, y+h/2, boxX, y+h/2)
+		}
+	}
+	return y + h
+}
+
+// renderVertical draws flow as a column of boxes from x, y with the step
+// names on the left and returns the y below it.
+func (d FlowDiagram) renderVertical(pdf *gofpdf.Fpdf, x, y, w float64, flow []string) float64 {
+	boxWidth := d.boxWidth(w)
+	boxX := x + flowStepNameWidth
+	steps := d.steps()
+
+	for i, box := range d.boxLines(pdf, flow, w) {
+		h := boxHeight(box)
+		if i > 0 {
+			drawArrow(pdf, boxX+boxWidth/2, y, boxX+boxWidth/2, y+flowArrowLength)
+			y += flowArrowLength
+		}
+		if i < len(steps) {
+			pdf.SetFont("THSarabunNew", "B", flowFontSize)
+			name := splitLines(pdf, steps[i], flowStepNameWidth)
+			drawLines(pdf, x, y+(h-flowLineHeight*float64(len(name)))/2, flowStepNameWidth, flowLineHeight, name)
+		}
+		drawFlowBox(pdf, boxX, y, boxWidth, h, box)
+		y += h
+	}
+	return y
+}
+
+// drawFlowBox draws a w x h box at x, y with lines centred in it.
+func drawFlowBox(pdf *gofpdf.Fpdf, x, y, w, h float64, lines []string) {
+	pdf.Rect(x, y, w, h, "D")
+	pdf.SetFont("THSarabunNew", "", flowFontSize)
+	lineY := y + (h-flowLineHeight*float64(len(lines)))/2
+	for _, line := range lines {
+		pdf.SetXY(x, lineY)
+		pdf.CellFormat(w, flowLineHeight, line, "", 0, "C", false, 0, "")
+		lineY += flowLineHeight
+	}
+}
+
+// drawArrow draws a line from x1, y1 to x2, y2 with a filled head at the
+// end.
+func drawArrow(pdf *gofpdf.Fpdf, x1, y1, x2, y2 float64) {
+	length := math.Hypot(x2-x1, y2-y1)
+	if length == 0 {
+		return
+	}
+	ux, uy := (x2-x1)/length, (y2-y1)/length
+	baseX, baseY := x2-ux*flowArrowHead*1.5, y2-uy*flowArrowHead*1.5
+	pdf.Line(x1, y1, baseX, baseY)
+
+	r, g, b := pdf.GetFillColor()
+	pdf.SetFillColor(pdf.GetDrawColor())
+	pdf.Polygon([]gofpdf.PointType{
+		{X: x2, Y: y2},
+		{X: baseX - uy*flowArrowHead, Y: baseY + ux*flowArrowHead},
+		{X: baseX + uy*flowArrowHead, Y: baseY - ux*flowArrowHead},
+	}, "F")
+	pdf.SetFillColor(r, g, b)
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CarbonOrigin is where the carbon of a fuel comes from. The CO2 from
+// burning biomass and biogas is biogenic: it is reported separately in
+// section 5.4 and not counted in the scope totals, while its CH4 and N2O
+// are.
+type CarbonOrigin int
+
+const (
+	Fossil CarbonOrigin = iota
+	Biogenic
+)
+
+// Gas is a greenhouse gas column of the table of section 5.1. FossilCH4 is
+// methane from fossil carbon and CH4 that from biogenic carbon, which have
+// different global warming potentials.
+type Gas int
+
+const (
+	CO2 Gas = iota
+	FossilCH4
+	CH4
+	N2O
+	SF6
+	NF3
+	HFCs
+	PFCs
+)
+
+var gasNames = []string{
+	CO2:       "CO2",
+	FossilCH4: "Fossil CH4",
+	CH4:       "CH4",
+	N2O:       "N2O",
+	SF6:       "SF6",
+	NF3:       "NF3",
+	HFCs:      "HFCs",
+	PFCs:      "PFCs",
+}
+
+func (g Gas) String() string {
+	if g < CO2 || g > PFCs {
+		return "Gas(" + strconv.Itoa(int(g)) + ")"
+	}
+	return gasNames[g]
+}
+
+// Fuel is a fuel burnt at the facilities, tagged with the origin of its
+// carbon.
+type Fuel struct {
+	Name   string
+	Origin CarbonOrigin
+}
+
+// FuelRecord is the direct emissions from burning Fuel at a scope 1 source,
+// in tCO2e by gas and before consolidation. Category is the group of the
+// source in section 5.1, e.g. "Mobile Combustion". Facility is empty for
+// data recorded for all facilities together.
+type FuelRecord struct {
+	Facility string
+	Category string
+	Source   string
+	Fuel     string
+	Gases    map[Gas]float64
+}
+
+// fuel returns the fuel named name.
+func (r Report) fuel(name string) (Fuel, bool) {
+	for _, f := range r.Fuels {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Fuel{}, false
+}
+
+// biogenicCO2 returns the CO2 of record that is biogenic and so outside the
+// scope totals. Only a fuel listed as biogenic counts; a fuel not listed is
+// an error of validation, which keeps the report from being rendered.
+func (r Report) biogenicCO2(record FuelRecord) float64 {
+	if f, ok := r.fuel(record.Fuel); ok && f.Origin == Biogenic {
+		return record.Gases[CO2]
+	}
+	return 0
+}
+
+// scopeEmissions returns the emissions of record counted in scope 1: all
+// gases but biogenic CO2.
+func (r Report) scopeEmissions(record FuelRecord) float64 {
+	total := 0.0
+	for _, v := range record.Gases {
+		total += v
+	}
+	return total - r.biogenicCO2(record)
+}
+
+// biogenicSource reports whether source burns a fuel listed as biogenic,
+// which lists it in section 3.2.2 instead of 3.2.1.
+func (r Report) biogenicSource(source ScopeSource) bool {
+	if source.Scope != 1 {
+		return false
+	}
+	for _, record := range r.FuelRecords {
+		if f, ok := r.fuel(record.Fuel); ok && f.Origin == Biogenic && sourceKey(record.Source) == sourceKey(source.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// directSources returns the scope 1 sources of section 3.2.2 when biogenic
+// is true, or those of section 3.2.1 when it is false.
+func (r Report) directSources(biogenic bool) []ScopeSource {
+	var sources []ScopeSource
+	for _, source := range r.sources(1) {
+		if r.biogenicSource(source) == biogenic {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// gasRow is a line of the table of section 5.1: the consolidated scope 1
+// emissions of a source by gas and in total, in tCO2e.
+type gasRow struct {
+	category, source string
+	gases            map[Gas]float64
+	total            float64
+}
+
+// gasRows returns the lines of the table of section 5.1 in the order their
+// sources are first recorded. Biogenic CO2 is left out; scope 1 records
+// without a breakdown by gas only have a total.
+func (r Report) gasRows() []*gasRow {
+	var rows []*gasRow
+	find := func(category, source string) *gasRow {
+		for _, row := range rows {
+			if row.category == category && row.source == source {
+				return row
+			}
+		}
+		rows = append(rows, &gasRow{category: category, source: source, gases: map[Gas]float64{}})
+		return rows[len(rows)-1]
+	}
+	for _, record := range r.FuelRecords {
+		share, _ := r.consolidationShare(record.Facility)
+		row := find(record.Category, record.Source)
+		for g, v := range record.Gases {
+			if g == CO2 && r.biogenicCO2(record) != 0 {
+				continue
+			}
+			row.gases[g] += share * v
+		}
+		row.total += share * r.scopeEmissions(record)
+	}
+	for _, record := range r.Records {
+		if record.Scope != 1 {
+			continue
+		}
+		share, _ := r.consolidationShare(record.Facility)
+		find("", record.Source).total += share * record.Emissions
+	}
+	return rows
+}
+
+// gasTable returns the table of section 5.1: the rows of gasRows grouped
+// by category.
+func (r Report) gasTable() [][]string {
+	header := []string{"แหล่งปล่อยก๊าซเรือนกระจก"}
+	for g := CO2; g <= PFCs; g++ {
+		header = append(header, g.String())
+	}
+	data := [][]string{append(header, "รวม (Ton CO2e)")}
+
+	rows := r.gasRows()
+	var categories []string
+	for _, row := range rows {
+		if !slices.Contains(categories, row.category) {
+			categories = append(categories, row.category)
+		}
+	}
+	value := func(v float64) string {
+		if v == 0 {
+			return "-"
+		}
+		return formatNumber(v, 2)
+	}
+	n, total := 0, make([]float64, PFCs+2)
+	for _, category := range categories {
+		if category != "" {
+			data = append(data, append([]string{category}, make([]string, PFCs+2)...))
+		}
+		for _, row := range rows {
+			if row.category != category {
+				continue
+			}
+			n++
+			cells := []string{strconv.Itoa(n) + ". " + strings.TrimSpace(row.source)}
+			for g := CO2; g <= PFCs; g++ {
+				cells = append(cells, value(row.gases[g]))
+				total[g] += row.gases[g]
+			}
+			total[PFCs+1] += row.total
+			data = append(data, append(cells, value(row.total)))
+		}
+	}
+	cells := []string{"รวมทั้งหมด"}
+	for _, v := range total {
+		cells = append(cells, value(v))
+	}
+	return append(data, cells)
+}
+
+// scope1Summary returns the table of section 5.1 as a SummaryTable of the
+// total of each source, so that it is validated like the other tables of
+// section 5.
+func (r Report) scope1Summary() SummaryTable {
+	table := SummaryTable{Scope: 1}
+	for _, row := range r.gasRows() {
+		table.Rows = append(table.Rows, SummaryRow{Source: strings.TrimSpace(row.source), Emissions: row.total})
+		table.Total += row.total
+	}
+	return table
+}
+
+// biogenicTable returns the table of section 5.4: the consolidated biogenic
+// CO2 of each source, which is not counted in the scope totals.
+func (r Report) biogenicTable() [][]string {
+	data := [][]string{{"แหล่งปล่อยก๊าซเรือนกระจก", "เชื้อเพลิง", "CO2 จากชีวมวล (Ton CO2)"}}
+	total := 0.0
+	for _, record := range r.FuelRecords {
+		co2 := r.biogenicCO2(record)
+		if co2 == 0 {
+			continue
+		}
+		share, _ := r.consolidationShare(record.Facility)
+		total += share * co2
+		data = append(data, []string{strings.TrimSpace(record.Source), record.Fuel, formatNumber(share*co2, 2)})
+	}
+	if len(data) == 1 {
+		data = append(data, []string{"-ไม่มี-", "", ""})
+	}
+	return append(data, []string{"รวมทั้งหมด", "", formatNumber(total, 2)})
+}
@@ -2,4 +2,9 @@ module github.com/NewbieCodeDev/go-pdf
 
 go 1.22.2
 
-require github.com/jung-kurt/gofpdf v1.16.2 // indirect
+require (
+	github.com/jung-kurt/gofpdf v1.16.2
+	software.sslmate.com/src/go-pkcs12 v0.5.0
+)
+
+require golang.org/x/crypto v0.11.0 // indirect
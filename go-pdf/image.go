@@ -0,0 +1,227 @@
+package main
+
+import (
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Image is a picture scaled to fit a Width x Height box without changing
+// its aspect ratio. A zero side does not limit the size; the image never
+// grows past the content width or the page. Caption, when set, is printed
+// under the image and always stays on the same page as it; Figure numbers
+// the caption instead.
+type Image struct {
+	Path   string
+	Width  float64
+	Height float64
+	// Align is "L", "C" or "R" for the image and its caption; empty means
+	// "C".
+	Align   string
+	Caption string
+	Figure  *Caption
+}
+
+const (
+	captionFontSize   = 14.0
+	captionLineHeight = 10.0
+	captionGap        = 2.0
+)
+
+// fitImage returns the size of an image of imageWidth x imageHeight scaled
+// to fit a w x h box, where a zero side does not limit the size.
+func fitImage(imageWidth, imageHeight, w, h float64) (float64, float64) {
+	scale := math.Inf(1)
+	if w > 0 {
+		scale = w / imageWidth
+	}
+	if h > 0 {
+		scale = math.Min(scale, h/imageHeight)
+	}
+	if math.IsInf(scale, 1) {
+		scale = 1
+	}
+	return imageWidth * scale, imageHeight * scale
+}
+
+// size returns the width and height the image is drawn at when the content
+// is w wide.
+func (im Image) size(pdf *gofpdf.Fpdf, w float64) (float64, float64) {
+	info := pdf.RegisterImageOptions(im.Path, gofpdf.ImageOptions{})
+	if info == nil {
+		return 0, 0
+	}
+	imageWidth, imageHeight := fitImage(info.Width(), info.Height(), im.Width, im.Height)
+
+	top, bottom := contentBounds(pdf)
+	return fitImage(imageWidth, imageHeight, w, bottom-top-im.captionHeight(pdf, w))
+}
+
+func (im Image) align() string {
+	if im.Align == "" {
+		return "C"
+	}
+	return im.Align
+}
+
+func (im Image) captionLines(pdf *gofpdf.Fpdf, w float64) []string {
+	if im.Figure != nil {
+		return im.Figure.lines(pdf, w)
+	}
+	if im.Caption == "" {
+		return nil
+	}
+	pdf.SetFont("THSarabunNew", "B", captionFontSize)
+	return splitLines(pdf, im.Caption, w)
+}
+
+func (im Image) captionHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	lines := im.captionLines(pdf, w)
+	if len(lines) == 0 {
+		return 0
+	}
+	return captionGap + captionLineHeight*float64(len(lines))
+}
+
+func (im Image) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	_, h := im.size(pdf, w)
+	return h + im.captionHeight(pdf, w)
+}
+
+func (im Image) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return im.height(pdf, w)
+}
+
+func (im Image) render(pdf *gofpdf.Fpdf, w float64) {
+	left, _, _, _ := pdf.GetMargins()
+	y := im.drawImage(pdf, left, pdf.GetY(), w)
+	y = im.drawCaption(pdf, left, y, w)
+	pdf.SetXY(left, y)
+}
+
+// drawImage draws the image aligned in a w wide column at x, y and returns
+// the y below it.
+func (im Image) drawImage(pdf *gofpdf.Fpdf, x, y, w float64) float64 {
+	imageWidth, imageHeight := im.size(pdf, w)
+	switch im.align() {
+	case "C":
+		x += (w - imageWidth) / 2
+	case "R":
+		x += w - imageWidth
+	}
+	if im.Figure != nil {
+		im.Figure.place(pdf)
+	}
+	pdf.ImageOptions(im.Path, x, y, imageWidth, imageHeight, false, gofpdf.ImageOptions{}, 0, "")
+	return y + imageHeight
+}
+
+// drawCaption draws the caption in a w wide column at x, y and returns the
+// y below it.
+func (im Image) drawCaption(pdf *gofpdf.Fpdf, x, y, w float64) float64 {
+	lines := im.captionLines(pdf, w)
+	if len(lines) == 0 {
+		return y
+	}
+	y += captionGap
+	for _, line := range lines {
+		pdf.SetXY(x, y)
+		pdf.CellFormat(w, captionLineHeight, line, "", 0, im.align(), false, 0, "")
+		y += captionLineHeight
+	}
+	return y
+}
+
+// ImageGrid lays images out in rows of equal cells, Gutter apart, starting
+// a new row when a row is full and a new page when a row does not fit. Each
+// image is fitted into its cell; the Width and Height of the images are
+// not used, but their captions are printed under the cell.
+type ImageGrid struct {
+	Images []Image
+	// Columns is the number of cells in a row. When zero, as many
+	// CellWidth wide cells as fit are used.
+	Columns    int
+	CellWidth  float64
+	CellHeight float64 // zero means square cells
+	Gutter     float64
+}
+
+// cellSize returns the number of columns and the cell size at width w.
+func (g ImageGrid) cellSize(w float64) (columns int, cellWidth, cellHeight float64) {
+	columns, cellWidth = g.Columns, g.CellWidth
+	switch {
+	case columns > 0 && cellWidth == 0:
+		cellWidth = (w - g.Gutter*float64(columns-1)) / float64(columns)
+	case columns == 0 && cellWidth > 0:
+		columns = max(1, int((w+g.Gutter)/(cellWidth+g.Gutter)))
+	case columns == 0:
+		columns, cellWidth = 1, w
+	}
+	cellHeight = g.CellHeight
+	if cellHeight == 0 {
+		cellHeight = cellWidth
+	}
+	return columns, cellWidth, cellHeight
+}
+
+// rows returns the images of each row with the row heights, captions
+// included.
+func (g ImageGrid) rows(pdf *gofpdf.Fpdf, w float64) (rows [][]Image, heights []float64) {
+	columns, cellWidth, cellHeight := g.cellSize(w)
+	for start := 0; start < len(g.Images); start += columns {
+		row := g.Images[start:min(start+columns, len(g.Images))]
+		height := 0.0
+		for _, im := range row {
+			height = math.Max(height, im.captionHeight(pdf, cellWidth))
+		}
+		rows = append(rows, row)
+		heights = append(heights, cellHeight+height)
+	}
+	return rows, heights
+}
+
+func (g ImageGrid) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	_, heights := g.rows(pdf, w)
+	h := 0.0
+	for i, height := range heights {
+		if i > 0 {
+			h += g.Gutter
+		}
+		h += height
+	}
+	return h
+}
+
+func (g ImageGrid) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	_, heights := g.rows(pdf, w)
+	if len(heights) == 0 {
+		return 0
+	}
+	return heights[0]
+}
+
+func (g ImageGrid) render(pdf *gofpdf.Fpdf, w float64) {
+	top, bottom := contentBounds(pdf)
+	left, _, _, _ := pdf.GetMargins()
+	_, cellWidth, cellHeight := g.cellSize(w)
+
+	rows, heights := g.rows(pdf, w)
+	y := pdf.GetY()
+	for i, row := range rows {
+		if i > 0 {
+			y += g.Gutter
+		}
+		if y+heights[i] > bottom && y > top {
+			pdf.AddPage()
+			y = top
+		}
+		for j, im := range row {
+			x := left + float64(j)*(cellWidth+g.Gutter)
+			im.Width, im.Height = cellWidth, cellHeight
+			im.drawImage(pdf, x, y, cellWidth)
+			im.drawCaption(pdf, x, y+cellHeight, cellWidth)
+		}
+		y += heights[i]
+	}
+	pdf.SetXY(left, y)
+}
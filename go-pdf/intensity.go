@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Denominator is a business metric that emissions are divided by to give a
+// carbon intensity, e.g. the tonnes of feed produced. Numerator is the unit
+// of the emissions, "kgCO2e" or "tCO2e", so that the intensity reads like
+// "kgCO2e/ton product". A zero value is not known yet.
+type Denominator struct {
+	Name      string
+	Unit      string
+	Numerator string
+	Value     float64 // in the report year
+	BaseValue float64 // in the base year
+}
+
+// numeratorFactors convert tCO2e to the numerator units.
+var numeratorFactors = map[string]float64{
+	"tCO2e":  1,
+	"kgCO2e": 1000,
+}
+
+// IntensityUnit returns the unit of the intensity, e.g. "kgCO2e/ton
+// product".
+func (d Denominator) IntensityUnit() string {
+	return d.Numerator + "/" + d.Unit
+}
+
+// intensity returns the emissions of scope in inv, or of all scopes when
+// scope is zero, per unit of value. It is false when value is not known or
+// inv does not cover the scope.
+func (d Denominator) intensity(inv Inventory, scope int, value float64) (float64, bool) {
+	factor, ok := numeratorFactors[d.Numerator]
+	if !ok || value <= 0 || (scope != 0 && !inv.hasScope(scope)) || len(inv.Sources) == 0 {
+		return 0, false
+	}
+	return factor * inv.ScopeTotal(scope) / value, true
+}
+
+// intensityTable returns the carbon intensity of the report inventory for
+// each denominator, per scope and in total, with the base year intensity
+// and the change. The base year total is only compared when the base year
+// covers the same scopes. The intensities of a denominator that is not
+// known yet read "รอข้อมูล".
+func (r Report) intensityTable(base Inventory) [][]string {
+	current := r.inventory()
+	data := [][]string{{"ตัวชี้วัด", scopeName(1), scopeName(2), scopeName(3), "รวม", "หน่วย"}}
+	value := func(v float64, ok bool, known float64) string {
+		switch {
+		case known <= 0:
+			return pendingData
+		case !ok:
+			return "-"
+		}
+		return formatNumber(v, 2)
+	}
+	sameScopes := true
+	for scope := 1; scope <= 3; scope++ {
+		if current.hasScope(scope) != base.hasScope(scope) {
+			sameScopes = false
+		}
+	}
+
+	for _, d := range r.Denominators {
+		rows := [][]string{
+			{d.Name + " ปี " + strconv.Itoa(current.Year)},
+			{d.Name + " ปีฐาน " + strconv.Itoa(base.Year)},
+			{"เปลี่ยนแปลง (%)"},
+		}
+		for _, scope := range []int{1, 2, 3, 0} {
+			c, hasCurrent := d.intensity(current, scope, d.Value)
+			b, hasBase := d.intensity(base, scope, d.BaseValue)
+			if scope == 0 && !sameScopes {
+				hasBase = false
+			}
+			change := "-"
+			if hasCurrent && hasBase && b != 0 {
+				change = signedNumber(100*(c-b)/b, 2) + "%"
+			}
+			rows[0] = append(rows[0], value(c, hasCurrent, d.Value))
+			rows[1] = append(rows[1], value(b, hasBase, d.BaseValue))
+			rows[2] = append(rows[2], change)
+		}
+		rows[0] = append(rows[0], d.IntensityUnit())
+		rows[1] = append(rows[1], d.IntensityUnit())
+		rows[2] = append(rows[2], "")
+		data = append(data, rows...)
+	}
+	return data
+}
+
+// intensityNotes returns the notes under the table of section 5.5, which
+// name the denominators still pending.
+func (r Report) intensityNotes() *TableNotes {
+	notes := &TableNotes{Title: "หมายเหตุ"}
+	var current, base []string
+	for _, d := range r.Denominators {
+		if d.Value <= 0 {
+			current = append(current, d.Name)
+		}
+		if d.BaseValue <= 0 {
+			base = append(base, d.Name)
+		}
+	}
+	if len(current) > 0 {
+		notes.Add("“" + pendingData + "” หมายถึง ยังรอข้อมูล" + strings.Join(current, ", ") + " ของปี " + strconv.Itoa(r.Year))
+	}
+	if len(base) > 0 {
+		notes.Add("ยังรอข้อมูล" + strings.Join(base, ", ") + " ของปีฐาน " + strconv.Itoa(r.BaseYear.Original.Year))
+	}
+	return notes
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ListStyle selects the marker drawn in front of each list item.
+type ListStyle int
+
+const (
+	ListBullet        ListStyle = iota // •
+	ListDash                           // -
+	ListNumberDot                      // 1.
+	ListNumberParen                    // 1)
+	ListThaiDot                        // ก.
+	ListNumberBracket                  // (1)
+)
+
+// thaiListLetters are the consonants used for ก., ข., ค. numbering. The
+// obsolete ฃ and ฅ are skipped as in Thai government documents.
+var thaiListLetters = []rune("กขคงจฉชซฌญฎฏฐฑฒณดตถทธนบปผฝพฟภมยรลวศษสหฬอฮ")
+
+// ListItem is one entry of a List. Children, when set, is drawn as a nested
+// list under the item text.
+type ListItem struct {
+	Text     string
+	Children *List
+	// Marker replaces the generated marker, e.g. to keep the numbering of
+	// text parsed by parseList.
+	Marker string
+}
+
+// List is a bullet or numbered list drawn with a hanging indent, so wrapped
+// lines align under the item text instead of under the marker.
+type List struct {
+	Style ListStyle
+	Items []ListItem
+	// Start is the number of the first item; zero means 1.
+	Start int
+}
+
+// listLine is one output line of a laid out list.
+type listLine struct {
+	markerX float64 // offset of the marker from the left edge of the list
+	marker  string  // only set on the first line of an item
+	textX   float64 // offset of the text from the left edge of the list
+	text    string
+}
+
+// listMarkerGap is the space between the widest marker and the item text.
+const listMarkerGap = 1.5
+
+func (s ListStyle) marker(n int) string {
+	switch s {
+	case ListDash:
+		return "-"
+	case ListNumberDot:
+		return strconv.Itoa(n) + "."
+	case ListNumberParen:
+		return strconv.Itoa(n) + ")"
+	case ListThaiDot:
+		return thaiListNumber(n) + "."
+	case ListNumberBracket:
+		return "(" + strconv.Itoa(n) + ")"
+	}
+	return "•"
+}
+
+// thaiListNumber returns the Thai letter sequence for n: ก … ฮ, then กก, กข …
+func thaiListNumber(n int) string {
+	var letters []rune
+	for n > 0 {
+		n--
+		letters = append([]rune{thaiListLetters[n%len(thaiListLetters)]}, letters...)
+		n /= len(thaiListLetters)
+	}
+	return string(letters)
+}
+
+// layoutList wraps the items of list to width w using the current font.
+// indent is the offset of the list from the left edge, used for nesting.
+func layoutList(pdf *gofpdf.Fpdf, list List, w float64, indent float64) []listLine {
+	start := list.Start
+	if start == 0 {
+		start = 1
+	}
+
+	markers := make([]string, len(list.Items))
+	markerWidth := 0.0
+	for i, item := range list.Items {
+		markers[i] = item.Marker
+		if markers[i] == "" {
+			markers[i] = list.Style.marker(start + i)
+		}
+		markerWidth = math.Max(markerWidth, pdf.GetStringWidth(markers[i]))
+	}
+
+	textX := indent + markerWidth + listMarkerGap
+	var lines []listLine
+	for i, item := range list.Items {
+		wrapped := splitLines(pdf, item.Text, w-textX)
+		if len(wrapped) == 0 {
+			wrapped = []string{""}
+		}
+		for j, text := range wrapped {
+			line := listLine{textX: textX, text: text}
+			if j == 0 {
+				line.markerX = indent
+				line.marker = markers[i]
+			}
+			lines = append(lines, line)
+		}
+		if item.Children != nil {
+			lines = append(lines, layoutList(pdf, *item.Children, w, textX)...)
+		}
+	}
+	return lines
+}
+
+func drawListLine(pdf *gofpdf.Fpdf, x, y, w, lineHeight float64, line listLine) {
+	if line.marker != "" {
+		pdf.SetXY(x+line.markerX, y)
+		pdf.CellFormat(line.textX-line.markerX, lineHeight, line.marker, "", 0, "L", false, 0, "")
+	}
+	pdf.SetXY(x+line.textX, y)
+	pdf.CellFormat(w-line.textX, lineHeight, line.text, "", 0, "L", false, 0, "")
+}
+
+// countListLines returns the number of lines list takes at width w, in the
+// same unit as estimateLines.
+func countListLines(pdf *gofpdf.Fpdf, list List, w float64) float64 {
+	return float64(len(layoutList(pdf, list, w, 0)))
+}
+
+func generateListContent(pdf *gofpdf.Fpdf, list List) {
+	lineHeight := 10.0
+
+	pdf.SetFont("THSarabunNew", "", 14)
+
+	top, bottom := contentBounds(pdf)
+	left, _, _, _ := pdf.GetMargins()
+	w := contentWidth(pdf)
+
+	for _, line := range layoutList(pdf, list, w, 0) {
+		y := pdf.GetY()
+		if y+lineHeight > bottom {
+			pdf.AddPage()
+			pdf.SetY(top)
+			y = pdf.GetY()
+		}
+		drawListLine(pdf, left, y, w, lineHeight, line)
+		pdf.SetY(y + lineHeight)
+	}
+}
+
+// generateListCell draws list inside a w x h cell at the current position
+// and leaves the position at the right edge of the cell, like CellFormat
+// with ln 0.
+func generateListCell(pdf *gofpdf.Fpdf, w, h, lineHeight float64, list List, borderStr string) {
+	x, y := pdf.GetXY()
+	pdf.CellFormat(w, h, "", borderStr, 0, "L", false, 0, "")
+
+	for i, line := range layoutList(pdf, list, w, 0) {
+		drawListLine(pdf, x, y+float64(i)*lineHeight, w, lineHeight, line)
+	}
+	pdf.SetXY(x+w, y)
+}
+
+var listMarkerPattern = regexp.MustCompile(`^\s*(\(\d+\)|\d+[.)]|[ก-ฮ]\.\s|[-•]\s)\s*`)
+
+// parseList recognises plain text such as "1.คุณวนิตา \n2.คุณสุวรรณา" or
+// "- ขนาด ..." as a list. Lines that do not start with a marker continue
+// the previous item on a line of their own, keeping the breaks of the
+// text. ok is false when the first line has no marker.
+func parseList(text string) (list List, ok bool) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		loc := listMarkerPattern.FindStringIndex(line)
+		if loc != nil && startsWithDigit(line[loc[1]:]) {
+			// "2.9 ระดับ..." is a section number, not a list marker.
+			loc = nil
+		}
+		if loc == nil {
+			if len(list.Items) == 0 {
+				return List{}, false
+			}
+			last := &list.Items[len(list.Items)-1]
+			last.Text += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		list.Items = append(list.Items, ListItem{
+			Marker: strings.TrimSpace(line[loc[0]:loc[1]]),
+			Text:   strings.TrimSpace(line[loc[1]:]),
+		})
+	}
+	return list, len(list.Items) > 0
+}
+
+func startsWithDigit(s string) bool {
+	for _, r := range s {
+		return unicode.IsDigit(r)
+	}
+	return false
+}
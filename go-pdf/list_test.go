@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		text  string
+		items []ListItem
+		ok    bool
+	}{
+		{"1.คุณวนิตา \n2.คุณสุวรรณา", []ListItem{{Marker: "1.", Text: "คุณวนิตา"}, {Marker: "2.", Text: "คุณสุวรรณา"}}, true},
+		{"- ขนาด 10 ตัน\n- ขนาด 20 ตัน", []ListItem{{Marker: "-", Text: "ขนาด 10 ตัน"}, {Marker: "-", Text: "ขนาด 20 ตัน"}}, true},
+		{"(1) ตรวจสอบ\nรายเดือน\n(2) สรุป", []ListItem{{Marker: "(1)", Text: "ตรวจสอบ\nรายเดือน"}, {Marker: "(2)", Text: "สรุป"}}, true},
+		{"ก. ข้อมูล\nข. ผล", []ListItem{{Marker: "ก.", Text: "ข้อมูล"}, {Marker: "ข.", Text: "ผล"}}, true},
+		{"2.9 ระดับความมีสาระสำคัญ", nil, false},
+		{"ข้อความ\n1. รายการ", nil, false},
+	}
+	for _, tt := range tests {
+		list, ok := parseList(tt.text)
+		if ok != tt.ok || !reflect.DeepEqual(list.Items, tt.items) {
+			t.Errorf("parseList(%q) = %+v, %v; want %+v, %v", tt.text, list.Items, ok, tt.items, tt.ok)
+		}
+	}
+}
+
+func TestThaiListNumber(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{{1, "ก"}, {2, "ข"}, {len(thaiListLetters), "ฮ"}, {len(thaiListLetters) + 1, "กก"}}
+	for _, tt := range tests {
+		if got := thaiListNumber(tt.n); got != tt.want {
+			t.Errorf("thaiListNumber(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
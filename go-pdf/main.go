@@ -1,170 +1,190 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
-	"log"
-	"math"
-
+	"os"
 	"strings"
 
 	"strconv"
+	"time"
+	"unicode"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
 func generateTableContent(pdf *gofpdf.Fpdf, dataArray [][]string, width []float64) {
-	margin := 20.0
-	headerHeight := 50.0
-	footerHeight := 40.0
+	generateNotedTableContent(pdf, dataArray, width, nil)
+}
 
-	pdf.SetFont("THSarabunNew", "", 14)
+// generateNotedTableContent draws the table like generateTableContent with
+// notes below it. The last row is moved to a new page together with the
+// notes when they do not fit under it.
+func generateNotedTableContent(pdf *gofpdf.Fpdf, dataArray [][]string, width []float64, notes *TableNotes) {
+	lineHeight := 10.0
 
 	// Calculate available height for content after header and before footer
-	_, pageHeight := pdf.GetPageSize()
-
-	contentEndY := pageHeight - footerHeight
-
-	for _, row := range dataArray {
-		//log.Println("row ",row)
-		contentHeight := 10.0
-		var lines []float64
-		maxLineIndex := 0
-
-		for i, data := range row {
+	top, bottom := contentBounds(pdf)
+	left, _, _, _ := pdf.GetMargins()
+	notesHeight := notes.height(pdf, contentWidth(pdf))
 
-			line := estimateLines(pdf, data, width[i])
-			lines = append(lines, line)
-
-			if line > lines[maxLineIndex] {
+	pdf.SetFont("THSarabunNew", "", 14)
 
-				maxLineIndex = i
+	for r, row := range dataArray {
+		lines, maxLineIndex := tableRowLines(pdf, row, width)
+		rowHeight := lineHeight * lines[maxLineIndex]
 
-			}
+		// Check if content exceeds remaining height and adjust if necessary
+		needed := rowHeight
+		if r == len(dataArray)-1 {
+			needed += notesHeight
+		}
+		if pdf.GetY()+needed > bottom && pdf.GetY() > top {
+			pdf.AddPage()
+			pdf.SetY(top)
 		}
 
-		// Check if content exceeds remaining height and adjust if necessary
+		x, y := left, pdf.GetY()
 		for i, data := range row {
-			_, y := pdf.GetXY()
-
-			remainingHeight := contentEndY - y
-			//log.Println("remainingHeight ", remainingHeight)
-			if contentHeight*lines[maxLineIndex] > remainingHeight {
-				//log.Println("if")
-				pdf.AddPage()
-				pdf.SetY(margin + headerHeight)
-				_, y = pdf.GetXY()
-				remainingHeight = contentEndY - y
-			}
-			currentX, currentY := pdf.GetXY()
-
-			if i == maxLineIndex {
-				contentHeight = 10.0
-				pdf.MultiCell(width[i], contentHeight, data, "1", "L", false)
+			pdf.SetXY(x, y)
+			if list, ok := parseList(data); ok {
+				generateListCell(pdf, width[i], rowHeight, lineHeight, list, "1")
 			} else {
-
-				pdf.MultiCell(width[i], 10.0*(lines[maxLineIndex]/lines[i]), data, "1", "L", false)
+				// Shorter cells are centred vertically in the row.
+				pdf.CellFormat(width[i], rowHeight, "", "1", 0, "L", false, 0, "")
+				textY := y + (rowHeight-lineHeight*lines[i])/2
+				drawLines(pdf, x, textY, width[i], lineHeight, splitLines(pdf, data, width[i]))
+				if marker := notes.marker(r, i); marker != "" {
+					drawNoteMarker(pdf, x, textY, width[i], lineHeight, data, marker)
+				}
 			}
-
-			pdf.SetXY(currentX+width[i], currentY)
-
+			x += width[i]
 		}
-		pdf.Ln(contentHeight * lines[maxLineIndex])
-
+		pdf.SetXY(left, y+rowHeight)
 	}
 
+	generateTableNotes(pdf, notes)
 }
 
-func estimateLines(pdf *gofpdf.Fpdf, text string, maxWidth float64) float64 {
+// tableRowLines returns the number of lines of each cell in row and the
+// index of the cell with the most lines.
+func tableRowLines(pdf *gofpdf.Fpdf, row []string, width []float64) (lines []float64, maxLineIndex int) {
+	for i, data := range row {
 
-	lines := 0.0
-	log.Println(pdf.GetFontSize())
+		line := estimateLines(pdf, data, width[i])
+		if list, ok := parseList(data); ok {
+			line = countListLines(pdf, list, width[i])
+		}
+		lines = append(lines, line)
 
-	textList := strings.Split(text, "\n")
-	for _, text := range textList {
-		textWidth := pdf.GetStringWidth(text)
-		lines += math.Ceil(textWidth / maxWidth)
+		if line > lines[maxLineIndex] {
 
-	}
+			maxLineIndex = i
 
-	log.Println(text)
-	log.Println(lines)
-	return float64(lines)
+		}
+	}
+	return lines, maxLineIndex
 }
 
-func generateTextContent(pdf *gofpdf.Fpdf, tabStartParagraph bool, content string) {
-	margin := 20.0
-	headerHeight := 50.0
-	footerHeight := 40.0
-	pageWidth, _, _ := pdf.PageSize(pdf.PageNo())
-	currentY := pdf.GetY()
-	//log.Println(currentY)
-
-	pdf.SetFont("THSarabunNew", "", 14)
-	indent := "           " // This is equivalent to a tab
-	var indentedContent string
-
-	// Calculate available height for content after header and before footer
-	_, pageHeight := pdf.GetPageSize()
-
-	contentEndY := pageHeight - footerHeight
-	remainingHeight := contentEndY - currentY
-
-	line1 := estimateLines(pdf, content, pageWidth-margin)
-	contentHeight := line1 * pdf.PointConvert(14)
+// estimateLines returns the number of lines text takes in a maxWidth wide
+// cell, wrapped the same way drawLines prints it.
+func estimateLines(pdf *gofpdf.Fpdf, text string, maxWidth float64) float64 {
+	return float64(len(splitLines(pdf, text, maxWidth)))
+}
 
-	if tabStartParagraph {
-		indentedContent = indent + content
-	} else {
-		indentedContent = content
+// drawLines prints lines one below the other in a w wide column at x, y.
+func drawLines(pdf *gofpdf.Fpdf, x, y, w, lineHeight float64, lines []string) {
+	for i, line := range lines {
+		pdf.SetXY(x, y+float64(i)*lineHeight)
+		pdf.CellFormat(w, lineHeight, line, "", 0, "L", false, 0, "")
 	}
-	// Check if content exceeds remaining height and adjust if necessary
-	if contentHeight > remainingHeight {
-		// Content exceeds remaining height, handle overflow
-
-		pdf.AddPage()
-		pdf.SetY(margin + headerHeight)
-		pdf.MultiCell(190, 10, indentedContent, "", "L", false)
+}
 
-	} else {
+// splitLines wraps text to lines that fit a w wide cell with the current
+// font, breaking at spaces where possible. Thai has no spaces between words,
+// so long runs are broken between characters, but never in front of a
+// combining vowel or tone mark.
+func splitLines(pdf *gofpdf.Fpdf, text string, w float64) []string {
+	maxWidth := w - 2*pdf.GetCellMargin()
+
+	var lines []string
+	for _, paragraph := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		runes := []rune(strings.TrimRight(paragraph, "\r"))
+		start, space := 0, -1
+		width := 0.0
+		for i := 0; i < len(runes); i++ {
+			width += pdf.GetStringWidth(string(runes[i]))
+			// Text extracted from the TGO template has stray spaces in
+			// front of marks, as in "ที ่"; those are not break points.
+			if unicode.IsSpace(runes[i]) && (i+1 == len(runes) || !unicode.Is(unicode.Mn, runes[i+1])) {
+				space = i
+			}
+			if width <= maxWidth || i == start {
+				continue
+			}
 
-		// Content fits within remaining height
-		pdf.SetY(currentY)
-		pdf.MultiCell(190, 10, indentedContent, "", "L", false)
+			end := space
+			if end <= start {
+				end = i
+				for end > start+1 && unicode.Is(unicode.Mn, runes[end]) {
+					end--
+				}
+			}
+			lines = append(lines, strings.TrimRight(string(runes[start:end]), " "))
 
+			start, space = end, -1
+			for start < len(runes) && unicode.IsSpace(runes[start]) {
+				start++
+			}
+			if start > i {
+				i = start - 1
+				width = 0
+			} else {
+				width = pdf.GetStringWidth(string(runes[start : i+1]))
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(runes[start:]), " "))
 	}
+	return lines
+}
 
+func generateTextContent(pdf *gofpdf.Fpdf, tabStartParagraph bool, content string) {
+	generateFlowContent(pdf, Paragraph{Text: content, Indent: tabStartParagraph})
 }
 
+// generateImageContent draws each image fitted into a w x h box, one below
+// the other when column is set and otherwise side by side, margin apart and
+// margin from the page edges, wrapping to new rows. The position is left
+// below the images.
 func generateImageContent(pdf *gofpdf.Fpdf, imgList []string, w float64, h float64, margin float64, column bool) {
 
 	pdf.Ln(5)
-	// The width is 100 mm (as set), height is automatically calculated
-
-	for i, path := range imgList {
-		//log.Print("path", path)
-
-		x := margin + float64(i)*(w+margin)
-
-		y := pdf.GetY()
-		pdf.Image(path, x, y, w, h, false, "", 0, "")
 
-		if column {
-			//log.Println("y", y)
-			y := pdf.GetY()
-			//log.Println("y", y)
-			// Retrieve the image information
-			info := pdf.GetImageInfo(path)
-
-			// The width is 100 mm (as set), height is automatically calculated
-			calculatedHeight := info.Height() * (100 / info.Width())
-			pdf.SetY(y + calculatedHeight + margin + 10)
+	if column {
+		for _, path := range imgList {
+			generateFlowContent(pdf, Image{Path: path, Width: w, Height: h}, Spacer{Height: margin})
 		}
+		return
+	}
 
+	grid := ImageGrid{CellWidth: w, CellHeight: h, Gutter: margin}
+	for _, path := range imgList {
+		grid.Images = append(grid.Images, Image{Path: path})
 	}
-	//pdf.Ln(h + 20)
+	left, top, right, _ := pdf.GetMargins()
+	pdf.SetMargins(margin, top, margin)
+	pdf.SetX(margin)
+	generateFlowContent(pdf, grid)
+	pdf.SetMargins(left, top, right)
+	pdf.SetX(left)
 }
 
-func main() {
+// generateReport lays out the whole report. Captions are numbered through
+// captions, whose lists and references come from the previous pass. The
+// pages are marked with watermark unless it is nil.
+func generateReport(captions *Captions, watermark *Watermark) *gofpdf.Fpdf {
+	captions.begin()
 
 	// Create a new PDF document
 	pdf := gofpdf.New("P", "mm", "A4", "")
@@ -173,23 +193,25 @@ func main() {
 	// Load a Thai font
 	pdf.AddUTF8Font("THSarabunNew", "", "./THSarabunNew.ttf")
 	pdf.AddUTF8Font("THSarabunNew", "B", "./THSarabunNew Bold.ttf")
+	reportInfo.setMetadata(pdf)
 
 	// Set font
 	pdf.SetFont("THSarabunNew", "B", 28)
 
 	generateHeader := func() {
+		watermark.draw(pdf, false)
 		if pdf.PageNo() > 1 {
 			pdf.SetFont("THSarabunNew", "B", 16)
 			pdf.CellFormat(25, 15, "", "TL", 0, "L", false, 0, "")
-			pdf.CellFormat(110, 15, "รายงานการปล่อยและดูดกลับก๊าซเรือนกระจก", "1", 0, "C", false, 0, "")
+			pdf.CellFormat(110, 15, reportInfo.Title, "1", 0, "C", false, 0, "")
 			pdf.SetFont("THSarabunNew", "B", 12)
-			pdf.MultiCell(25, 5, "TCFO_R_02 Version 03.00 24/4/2019", "TRB", "L", false)
+			pdf.MultiCell(25, 5, reportInfo.Form()+" "+reportInfo.FormDate, "TRB", "L", false)
 
 			pdf.SetFont("THSarabunNew", "", 12)
 			pdf.CellFormat(25, 15, "", "L", 0, "L", false, 0, "")
 			pdf.CellFormat(25, 15, "องค์กร", "1", 0, "L", false, 0, "")
 			x, y := pdf.GetXY()
-			pdf.MultiCell(85, 7.5, "บริษัท เบทาโกร จำกัด (มหาชน) โรงงานผลิตอาหารสัตว์ จ.ลพบุรี 1,2 และ 3", "1", "L", false)
+			pdf.MultiCell(85, 7.5, reportInfo.Organisation, "1", "L", false)
 			pdf.SetXY(x+85, y)
 			pdf.CellFormat(25, 15, "หน้าที่ "+strconv.Itoa(pdf.PageNo()-1), "TR", 0, "L", false, 0, "")
 			pdf.Ln(-1)
@@ -198,11 +220,12 @@ func main() {
 			pdf.CellFormat(25, 5, "", "LB", 0, "L", false, 0, "")
 			pdf.CellFormat(25, 5, "หน่วยงานสอบทาน", "1", 0, "L", false, 0, "")
 			x, y = pdf.GetXY()
-			pdf.MultiCell(85, 5, "บริษัท อีซีอีอี จำกัด", "1", "L", false)
+			pdf.MultiCell(85, 5, reportInfo.Verifier, "1", "L", false)
 			pdf.SetXY(x+85, y)
 			pdf.CellFormat(25, 5, "", "TRB", 0, "L", false, 0, "")
 
-			pdf.Ln(20)
+			_, top, _, _ := pdf.GetMargins()
+			pdf.SetY(top + headerHeight)
 		}
 	}
 
@@ -213,10 +236,11 @@ func main() {
 			// pdf.SetFont("Arial", "", 8)
 			pdf.SetFont("THSarabunNew", "B", 14)
 			pdf.CellFormat(25, 7, "จัดทำโดย", "1", 0, "C", false, 0, "")
-			pdf.CellFormat(65, 7, "สภาอุตสาหกรรมแห่งประเทศไทย", "1", 0, "L", false, 0, "")
+			pdf.CellFormat(65, 7, reportInfo.Preparer, "1", 0, "L", false, 0, "")
 			pdf.CellFormat(25, 7, "ผู้ทวนสอบ", "1", 0, "C", false, 0, "")
-			pdf.CellFormat(50, 7, "บริษัท อีซีอีอี จำกัด", "1", 0, "C", false, 0, "")
+			pdf.CellFormat(50, 7, reportInfo.Verifier, "1", 0, "C", false, 0, "")
 		}
+		watermark.draw(pdf, true)
 	}
 
 	// Set header and footer functions
@@ -232,33 +256,33 @@ func main() {
 	// Write Thai text
 	// Add title
 
-	pdf.CellFormat(0, 10, "รายงานการปล่อยและดูดกลับก๊าซเรือนกระจก", "", 2, "C", false, 0, "")
+	pdf.CellFormat(0, 10, reportInfo.Title, "", 2, "C", false, 0, "")
 	pdf.Ln(10)
 
 	// Add images
 	imagePaths := []string{"rabbit.jpg", "rabbit.jpg", "rabbit.jpg", "rabbit.jpg"}
 	generateImageContent(pdf, imagePaths, 45.0, 45.0, 5.0, false)
-	pdf.Ln(60)
+	pdf.Ln(15)
 
 	pdf.SetFont("THSarabunNew", "B", fontSize)
 	pdf.CellFormat(25, 10, "ชื่อองค์กร : ", "", 0, "L", false, 0, "")
 	pdf.SetFont("THSarabunNew", "", fontSize)
-	pdf.MultiCell(0, 10, "บริษัท เบทาโกร จำกัด (มหาชน) โรงงานผลิตอาหารสัตว์ จ.ลพบุรี 1,2 และ 3", "", "L", false)
+	pdf.MultiCell(0, 10, reportInfo.Organisation, "", "L", false)
 
 	pdf.SetFont("THSarabunNew", "B", fontSize)
 	pdf.CellFormat(50, 10, "ที่อยู่/สถานที่ตั้งองค์กร : ", "", 0, "L", false, 0, "")
 	pdf.SetFont("THSarabunNew", "", fontSize)
-	pdf.MultiCell(0, 10, "เลขที่ 3 หมู่ 13 ถ.สระบุรี-หล่มสัก ต.ช่องสาริกา อ.พัฒนานิคม จ.ลพบุรี", "", "L", false)
+	pdf.MultiCell(0, 10, reportInfo.Address, "", "L", false)
 
 	pdf.SetFont("THSarabunNew", "B", fontSize)
 	pdf.CellFormat(35, 10, "วันที่รายงานผล : ", "", 0, "L", false, 0, "")
 	pdf.SetFont("THSarabunNew", "", fontSize)
-	pdf.CellFormat(0, 10, "28 มิ.ย. 2566", "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 10, thaiDate(reportInfo.Date), "", 1, "L", false, 0, "")
 
 	pdf.SetFont("THSarabunNew", "B", fontSize)
 	pdf.CellFormat(55, 10, "ระยะเวลาในการติดตามผล : ", "", 0, "L", false, 0, "")
 	pdf.SetFont("THSarabunNew", "", fontSize)
-	pdf.CellFormat(0, 10, "มกราคม ถึง ธันวาคม 2565", "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 10, reportInfo.Period, "", 1, "L", false, 0, "")
 	pdf.Ln(20)
 
 	pdf.SetFont("THSarabunNew", "B", 18)
@@ -272,7 +296,10 @@ func main() {
 	// Page 1
 	margin = 20.0
 	pdf.SetMargins(margin, margin, margin)
-	pdf.SetAutoPageBreak(true, margin)
+	pdf.SetAutoPageBreak(true, footerHeight)
+
+	// Front matter
+	generateCaptionLists(pdf, captions)
 
 	pdf.AddPage()
 
@@ -280,29 +307,19 @@ func main() {
 	// Table header
 
 	// Title
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(0, 10, "1. บทนำ", "", 1, "L", false, 0, "")
-
-	// Add some space before the paragraph
-	pdf.CellFormat(0, 8, "", "0", 1, "C", false, 0, "")
-
-	// Content
-	// paragraph 1
-	content := `จากผลกระทบของภาวะโลกร้อน ทำให้ประเทศต่างๆ ทั่วโลกตื่นตัวในการดำเนินงานเพื่อลดการปล่อยก๊าซเรือนกระจก แนวคิดการจัดทำคาร์บอนฟุตพริ้นท์ขององค์กร (Carbon Footprint for Organization: CFO) เป็นวิธีการประเมินปริมาณก๊าซเรือนกระจกที่ปล่อยจากกิจกรรมทั้งหมดขององค์กรและคำนวณออกมาในรูปคาร์บอนไดออกไซด์เทียบเท่า อันจะนำไปสู่การกำหนดแนวทางการบริหารจัดการ เพื่อลดการปล่อยก๊าซเรือนกระจกได้อย่างมีประสิทธิภาพทั้งในระดับหน่วยงาน บริษัท หรือโรงงาน ระดับอุตสาหกรรม และระดับประเทศ `
-	generateTextContent(pdf, true, content)
-
-	// paragraph 2
-	content = `จากผลกระทบของภาวะโลกร้อน ทำให้ประเทศต่างๆ ทั่วโลกตื่นตัวในการดำเนินงานเพื่อลดการปล่อยก๊าซเรือนกระจก แนวคิดการจัดทำคาร์บอนฟุตพริ้นท์ขององค์กร (Carbon Footprint for Organization: CFO) เป็นวิธีการประเมินปริมาณก๊าซเรือนกระจกที่ปล่อยจากกิจกรรมทั้งหมดขององค์กรและคำนวณออกมาในรูปคาร์บอนไดออกไซด์เทียบเท่า อันจะนำไปสู่การกำหนดแนวทางการบริหารจัดการ เพื่อลดการปล่อยก๊าซเรือนกระจกได้อย่างมีประสิทธิภาพทั้งในระดับหน่วยงาน บริษัท หรือโรงงาน ระดับอุตสาหกรรม และระดับประเทศ `
-	generateTextContent(pdf, true, content)
-
-	// paragraph 3
-	content = `จากผลกระทบของภาวะโลกร้อน ทำให้ประเทศต่างๆ ทั่วโลกตื่นตัวในการดำเนินงานเพื่อลดการปล่อยก๊าซเรือนกระจก แนวคิดการจัดทำคาร์บอนฟุตพริ้นท์ขององค์กร (Carbon Footprint for Organization: CFO) เป็นวิธีการประเมินปริมาณก๊าซเรือนกระจกที่ปล่อยจากกิจกรรมทั้งหมดขององค์กรและคำนวณออกมาในรูปคาร์บอนไดออกไซด์เทียบเท่า อันจะนำไปสู่การกำหนดแนวทางการบริหารจัดการ เพื่อลดการปล่อยก๊าซเรือนกระจกได้อย่างมีประสิทธิภาพทั้งในระดับหน่วยงาน บริษัท หรือโรงงาน ระดับอุตสาหกรรม และระดับประเทศ `
-	generateTextContent(pdf, true, content)
-
-	//page 2
-
-	content = `กรกฎาคม 2565) ขององค์การบริหารจัดการก๊าซเรือนกระจก (องค์การมหาชน) และขอรับการทวนสอบข้อมูลเป็นระดับการทวนสอบแบบจำกัด (Limited level of assurance) และมีความมีสาระสำคัญ(Materiality) 5% `
-	generateTextContent(pdf, false, content)
+	generateFlowContent(pdf,
+		Heading{Text: "1. บทนำ"},
+		// Add some space before the paragraph
+		Spacer{Height: 8},
+		// paragraph 1
+		Paragraph{Text: `จากผลกระทบของภาวะโลกร้อน ทำให้ประเทศต่างๆ ทั่วโลกตื่นตัวในการดำเนินงานเพื่อลดการปล่อยก๊าซเรือนกระจก แนวคิดการจัดทำคาร์บอนฟุตพริ้นท์ขององค์กร (Carbon Footprint for Organization: CFO) เป็นวิธีการประเมินปริมาณก๊าซเรือนกระจกที่ปล่อยจากกิจกรรมทั้งหมดขององค์กรและคำนวณออกมาในรูปคาร์บอนไดออกไซด์เทียบเท่า อันจะนำไปสู่การกำหนดแนวทางการบริหารจัดการ เพื่อลดการปล่อยก๊าซเรือนกระจกได้อย่างมีประสิทธิภาพทั้งในระดับหน่วยงาน บริษัท หรือโรงงาน ระดับอุตสาหกรรม และระดับประเทศ `, Indent: true},
+		// paragraph 2
+		Paragraph{Text: `จากผลกระทบของภาวะโลกร้อน ทำให้ประเทศต่างๆ ทั่วโลกตื่นตัวในการดำเนินงานเพื่อลดการปล่อยก๊าซเรือนกระจก แนวคิดการจัดทำคาร์บอนฟุตพริ้นท์ขององค์กร (Carbon Footprint for Organization: CFO) เป็นวิธีการประเมินปริมาณก๊าซเรือนกระจกที่ปล่อยจากกิจกรรมทั้งหมดขององค์กรและคำนวณออกมาในรูปคาร์บอนไดออกไซด์เทียบเท่า อันจะนำไปสู่การกำหนดแนวทางการบริหารจัดการ เพื่อลดการปล่อยก๊าซเรือนกระจกได้อย่างมีประสิทธิภาพทั้งในระดับหน่วยงาน บริษัท หรือโรงงาน ระดับอุตสาหกรรม และระดับประเทศ `, Indent: true},
+		// paragraph 3
+		Paragraph{Text: `จากผลกระทบของภาวะโลกร้อน ทำให้ประเทศต่างๆ ทั่วโลกตื่นตัวในการดำเนินงานเพื่อลดการปล่อยก๊าซเรือนกระจก แนวคิดการจัดทำคาร์บอนฟุตพริ้นท์ขององค์กร (Carbon Footprint for Organization: CFO) เป็นวิธีการประเมินปริมาณก๊าซเรือนกระจกที่ปล่อยจากกิจกรรมทั้งหมดขององค์กรและคำนวณออกมาในรูปคาร์บอนไดออกไซด์เทียบเท่า อันจะนำไปสู่การกำหนดแนวทางการบริหารจัดการ เพื่อลดการปล่อยก๊าซเรือนกระจกได้อย่างมีประสิทธิภาพทั้งในระดับหน่วยงาน บริษัท หรือโรงงาน ระดับอุตสาหกรรม และระดับประเทศ `, Indent: true},
+		// page 2
+		Paragraph{Text: `กรกฎาคม 2565) ขององค์การบริหารจัดการก๊าซเรือนกระจก (องค์การมหาชน) และขอรับการทวนสอบข้อมูลเป็นระดับการทวนสอบแบบจำกัด (Limited level of assurance) และมีความมีสาระสำคัญ(Materiality) ` + reportData.materialityPercent() + `% `},
+	)
 
 	pdf.SetFont("THSarabunNew", "B", 16)
 	// Add some space before the paragraph
@@ -323,7 +340,7 @@ func main() {
 		{"2.6 ระยะเวลาติดตามผล ", "มกราคม ถึง ธันวาคม 2565 "},
 		{"2.7 แนวทางที่ใช้ในการติดตามผล ", "ข้อกำหนดในการคำนวณและรายงานคาร์บอนฟุตพริ้นท์ขององค์กร พิมพ์ครั้งที่ 8 (ฉบับปรับปรุงครั้งที่ 6 กรกฎาคม 2565)  "},
 		{"2.8 ระดับของการรับรอง (Level of Assurance)", "แบบจำกัด (Limited Assurance)"},
-		{"2.9 ระดับความมีสาระสำคัญ (Materiality Threshold)  ", "5% Materiality"},
+		{"2.9 ระดับความมีสาระสำคัญ (Materiality Threshold)  ", reportData.materialityPercent() + "% Materiality"},
 	}
 
 	//generateTableContent(pdf, data, []float64{60.0, 120.0})
@@ -332,13 +349,14 @@ func main() {
 
 	//page 3
 	pdf.AddPage()
+	captions.Chapter(3)
 	pdf.SetFont("THSarabunNew", "B", 16)
 	pdf.CellFormat(0, 10, "3. ขอบเขต  ", "", 1, "L", false, 0, "")
 	pdf.CellFormat(0, 10, " 3.1 ขอบเขตขององค์กร ", "", 1, "L", false, 0, "")
 
 	data = [][]string{
-		{"1) แนวทางที่ใช้กำหนดขอบเขตองค์กร ", "ควบคุมดำเนินงาน (OPERATIONAL CONTROL) "},
-		{"2) หน่วยสาธารณูปโภค (Facility)/พื้นที่ที่ครอบคลุมในรายงาน ", "1. บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 1 \n2. บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 2 \n3. บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 3"},
+		{"1) แนวทางที่ใช้กำหนดขอบเขตองค์กร ", reportData.Consolidation.String()},
+		{"2) หน่วยสาธารณูปโภค (Facility)/พื้นที่ที่ครอบคลุมในรายงาน ", reportData.facilityList()},
 		{"3) เอกสารยืนยันขอบเขต ", "โรงงานลพบุรี 1,2,3 : ใบอนุญาตประกอบกิจการโรงงานเลขที ่ :  ส3-15(1)-1/34ลบ "},
 	}
 
@@ -347,52 +365,37 @@ func main() {
 
 	//page 4
 	pdf.AddPage()
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(0, 10, "3.1.1 โครงสร้างขององค์กร", "", 1, "L", false, 0, "")
-
-	// Add images
-	imagePaths = []string{"companyStructure.png"}
-	generateImageContent(pdf, imagePaths, 150.0, 0.0, 15.0, true)
+	generateFlowContent(pdf,
+		Heading{Text: "3.1.1 โครงสร้างขององค์กร"},
+		Spacer{Height: 5},
+		OrgChart{Root: reportOrganisation, Figure: captions.Figure("structure", "โครงสร้างขององค์กร")},
+	)
 
 	// end page 4
 
 	//page 5
 	pdf.AddPage()
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(0, 10, "3.1.2 แผนผังของโรงงาน ", "", 1, "L", false, 0, "")
-
-	// Add images
-	imagePaths = []string{"companyMap.png"}
-	generateImageContent(pdf, imagePaths, 0.0, 180.0, 15.0, true)
+	generateFlowContent(pdf,
+		Heading{Text: "3.1.2 แผนผังของโรงงาน "},
+		Spacer{Height: 5},
+		Image{Path: "companyMap.png", Height: 180, Figure: captions.Figure("site-map", "แผนผังของโรงงาน")},
+	)
 
 	// end page 5
 
 	//page 6
 	pdf.AddPage()
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(0, 10, "3.1.3 แผนผังกระบวนการผลิต ", "", 1, "L", false, 0, "")
-
-	// Add images
-	imagePaths = []string{"productionMap.png"}
-	generateImageContent(pdf, imagePaths, 150.0, 0.0, 15.0, true)
-	pdf.SetFont("THSarabunNew", "B", 14)
-	pdf.CellFormat(0, 10, "รูปแสดง : แผนผังการผลิต บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 1", "", 1, "C", false, 0, "")
-
-	// Add images
-	imagePaths = []string{"productionMap1.png"}
-	generateImageContent(pdf, imagePaths, 150.0, 0.0, 15.0, true)
-	pdf.SetFont("THSarabunNew", "B", 14)
-	pdf.CellFormat(0, 10, "รูปแสดง : แผนผังการผลิต บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 2", "", 1, "C", false, 0, "")
-
-	// end page 6
-
-	//page 7
-	pdf.AddPage()
-	// Add images
-	imagePaths = []string{"productionMap2.png"}
-	generateImageContent(pdf, imagePaths, 150.0, 0.0, 15.0, true)
-	pdf.SetFont("THSarabunNew", "B", 14)
-	pdf.CellFormat(0, 10, "รูปแสดง : แผนผังการผลิต บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 3", "", 1, "C", false, 0, "")
+	generateFlowContent(pdf,
+		Heading{Text: "3.1.3 แผนผังกระบวนการผลิต "},
+		Paragraph{Text: "แผนผังการผลิตของโรงงานลพบุรี 1, 2 และ 3 แสดงใน" + captions.Ref("production-lr1") + " ถึง" + captions.Ref("production-lr3"), Indent: true},
+		Spacer{Height: 5},
+		Image{Path: "productionMap.png", Width: 150, Figure: captions.Figure("production-lr1", "แผนผังการผลิต บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 1")},
+		Spacer{Height: 10},
+		Image{Path: "productionMap1.png", Width: 150, Figure: captions.Figure("production-lr2", "แผนผังการผลิต บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 2")},
+		Spacer{Height: 10},
+		Image{Path: "productionMap2.png", Width: 150, Figure: captions.Figure("production-lr3", "แผนผังการผลิต บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 3")},
+		Spacer{Height: 10},
+	)
 
 	pdf.SetFont("THSarabunNew", "B", 16)
 	pdf.CellFormat(0, 10, "3.1.4 ระบุกิจกรรมทั้งหมดขององค์กร  ", "", 1, "L", false, 0, "")
@@ -404,46 +407,11 @@ func main() {
 
 	// Set text color to black
 	pdf.SetTextColor(0, 0, 0)
-	data = [][]string{
-		{"Facility ", "กิจกรรมขององค์กรในแต่ละ Facility ", "", ""},
-		{"", "Scope 1 ", "Scope 2 ", "Scope 3"},
-		{"1. บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 1 (LR1)", "1. การเผาไหม้น้ำมันดีเซลรถยนต์ ", "1. การใช้ไฟฟ้า ", "1. Purchased goods and services "},
-		{"", "2. การเผาไหม้น้ำมันเบนซีนรถยนต์ ", "", "2. Fuel- and energy related activities not included scope 1 & 2"},
-		{"", "3. การเผาไหม้ก๊าซ LPG สำหรับรถยนต์", "", "3. Upstream transport "},
-		{"", "4. การเผาไหม้ก๊าซ NGV สำหรับรถยนต", "", "4. Waste generate"},
-		{"", "5. การเผาไหม้น้ำมันดีเซล generator + fire pump ", "", "5. Downstream transport"},
-		{"", "6. การเผาไหม้น้ำมันดีเซลเครื่องตัดหญ้า ", "", ""},
-		{"", "7. การเผาไหม้น้ำมันเตา C Boiler และการอบข้าวโพด", "", "4. Waste generate"},
-	}
-	pdf.SetFont("THSarabunNew", "", 12)
-	// Set fill color (RGB)
-	pdf.SetFillColor(190, 190, 190)
-	pdf.CellFormat(50, 15, "Facility ", "TL", 0, "C", true, 0, "")
-	pdf.CellFormat(120, 15, "กิจกรรมขององค์กรในแต่ละ Facility ", "1", 0, "C", true, 0, "")
-	pdf.Ln(-1)
-
-	pdf.CellFormat(50, 7.5, "", "LB", 0, "L", true, 0, "")
-	pdf.CellFormat(50, 7.5, "Scope 1 ", "1", 0, "C", true, 0, "")
-	x, y := pdf.GetXY()
-	pdf.MultiCell(25, 7.5, "Scope 2 ", "1", "C", true)
-	pdf.SetXY(x+25, y)
-	pdf.CellFormat(45, 7.5, "Scope 3", "1", 0, "C", true, 0, "")
-	pdf.Ln(-1)
-
-	// Set fill color (RGB)
-	pdf.SetFillColor(255, 255, 255)
-
-	data = [][]string{
-		{"1. บริษัท เบทาโกร จำกัด (มหาชน) \nโรงงานลพบุรี 1 (LR1)", "1.การเผาไหม้น้ำมันดีเซลรถยนต", "1.การใช้ไฟฟ้า", " 1.Purchased goods and services"},
-		{" ", "2.การเผาไหม้น้ำมันดีเซลรถยนต์", " ", " 2. Purchased goods and services"},
-	}
-
-	//generateTableContent(pdf, data, []float64{50.0, 50.0, 25.0, 45.0})
-
-	//pdf.Ln(10)
-
-	pdf.SetFont("THSarabunNew", "", 10)
-	pdf.CellFormat(45, 7.5, "หมายเหตุ *กิจกรรมขององค์กรใน Scope 3 ที่ไม่รวมไว้ในการติดตามผล ", "", 0, "L", true, 0, "")
+	generateFlowContent(pdf, Table{
+		Data:  reportData.activityTable(),
+		Width: []float64{50.0, 50.0, 25.0, 45.0},
+		Notes: reportData.activityNotes(),
+	})
 
 	//end page 7
 
@@ -451,9 +419,10 @@ func main() {
 
 	//page 8
 	pdf.AddPage()
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.MultiCell(0, 10, "3.1.5 ระบุขอบเขตขององค์กรที่เพิ่มเข้ามาหรือขอบเขตที่ไม่รวม (ระบุ Facility) ที่เพิ่มเข้ามาหรือไม่นับรวม) พร้อมเหตุผล", "", "L", false)
-	generateTextContent(pdf, true, "1. ไม่นับรวมการปล่อยก๊าซเรือนกระจกการใช้ก๊าซ LPG กิจกรรมซ่อมบำรุง โรงงานลพบุรี 1 ,2 เนื่องจากมีการใช้งานน้อยมาก มีอายุการใช้งานมากกว่า 1 ปี ")
+	generateFlowContent(pdf,
+		Heading{Text: "3.1.5 ระบุขอบเขตขององค์กรที่เพิ่มเข้ามาหรือขอบเขตที่ไม่รวม (ระบุ Facility) ที่เพิ่มเข้ามาหรือไม่นับรวม) พร้อมเหตุผล"},
+		Paragraph{Text: "1. ไม่นับรวมการปล่อยก๊าซเรือนกระจกการใช้ก๊าซ LPG กิจกรรมซ่อมบำรุง โรงงานลพบุรี 1 ,2 เนื่องจากมีการใช้งานน้อยมาก มีอายุการใช้งานมากกว่า 1 ปี ", Indent: true},
+	)
 
 	pdf.SetFont("THSarabunNew", "B", 16)
 	pdf.MultiCell(0, 10, "3.2 ขอบเขตการดำเนินงาน", "", "L", false)
@@ -470,67 +439,10 @@ func main() {
 	pdf.AddPage()
 	pdf.SetFont("THSarabunNew", "B", 14)
 	pdf.MultiCell(0, 10, "3.2.1   ระบุกิจกรรมที่เป็นแหล่งปล่อยก๊าซเรือนกระจกประเภทที่1 ขององค์กร ", "", "L", false)
-
-	pdf.SetFillColor(190, 190, 190)
-	pdf.CellFormat(20, 15*4, "Facility ", "1", 0, "C", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 15, "แหล่งปล่อยก๊าซเรือนกระจก (Emission Source) เช่น ระบุ อุปกรณ์หลัก/ เครื่องจักร / กระบวนการ/กิจกรรม ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 15*4, "ที่ตั้ง/ตำแหน่ง", "1", "C", true)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*4, "ใช้ภายใน ", "1", "C", true)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*2, "จำหน่ายภายนอก", "1", "C", true)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 20, "ความสำคัญ (มีนัยสำคัญมาก หรือ น้อย) ", "1", "C", true)
-
-	pdf.SetFillColor(255, 255, 255)
-	pdf.SetFont("THSarabunNew", "B", 14)
-	pdf.CellFormat(170, 10, "Mobile Combustion ", "1", 0, "L", true, 0, "")
-	pdf.Ln(-1)
-
-	pdf.CellFormat(20, 15, "LR1,2,3  ", "1", 0, "C", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 15, "1. น้ำมันดีเซลรถยนต์  ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 15, "-", "1", "C", true)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15, " ", "1", "C", true)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15, "", "1", "C", true)
-	pdf.SetXY(x+20, y)
-	pdf.MultiCell(30, 15, "น้อย", "1", "C", true)
-
-	pdf.SetFont("THSarabunNew", "B", 14)
-	pdf.CellFormat(170, 10, "Stationary Combustion ", "1", 0, "L", true, 0, "")
-	pdf.Ln(-1)
-
-	pdf.CellFormat(20, 15, "LR1,2,3  ", "1", 0, "C", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 15, "1. น้ำมันดีเซลรถยนต์  ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 15, "-", "1", "C", true)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15, " ", "1", "C", true)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15, "", "1", "C", true)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 15, "น้อย", "1", "C", true)
-	pdf.Ln(-1)
-	pdf.SetFont("THSarabunNew", "", 10)
-	pdf.CellFormat(45, 7.5, "หมายเหตุ :  1. มีนัยสำคัญ “มาก” หมายถึง มีปริมาณการปล่อยก๊าซเรือนกระจกตั้งแต่ร้อยละ 5 ของปริมาณการปล่อยก๊าซเรือนกระจกรวมประเภทที่ 1+2 ขององค์กร", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "2. มีนัยสำคัญ “น้อย” หมายถึง มีปริมาณการปล่อยก๊าซเรือนกระจกน้อยกว่าร้อยละ 5 ของปริมาณการปล่อยก๊าซเรือนกระจกรวมประเภทที่ 1+2 ขององค์กร", "", 2, "L", false, 0, "")
+	generateFlowContent(pdf,
+		captions.Table("scope1-activities", "กิจกรรมที่เป็นแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 1"),
+		SourceTable{Report: reportData, Sources: reportData.directSources(false), Notes: reportData.materialityNotes(reportData.directSources(false))},
+	)
 
 	pdf.AddPage()
 	pdf.SetFont("THSarabunNew", "B", 14)
@@ -543,38 +455,7 @@ func main() {
 
 	// Set text color to black
 	pdf.SetTextColor(0, 0, 0)
-	pdf.SetFillColor(190, 190, 190)
-	pdf.CellFormat(20, 15*4, "Facility ", "1", 0, "C", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 15, "แหล่งปล่อยก๊าซเรือนกระจก (Emission Source) เช่น ระบุ อุปกรณ์หลัก/ เครื่องจักร / กระบวนการ/กิจกรรม ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 15*4, "ที่ตั้ง/ตำแหน่ง", "1", "C", true)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*4, "ใช้ภายใน ", "1", "C", true)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*2, "จำหน่ายภายนอก", "1", "C", true)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 20, "ความสำคัญ (มีนัยสำคัญมาก หรือ น้อย) ", "1", "C", true)
-
-	pdf.CellFormat(20, 10, " ", "1", 0, "C", false, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 10, "-ไม่มี-", "1", "C", false)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 10, "", "1", "C", false)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 10, " ", "1", "C", false)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 10, "", "1", "C", false)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 10, "", "1", "C", false)
+	generateFlowContent(pdf, SourceTable{Report: reportData, Sources: reportData.directSources(true), Notes: reportData.materialityNotes(reportData.directSources(true))})
 
 	//end page 9
 
@@ -590,79 +471,13 @@ func main() {
 
 	// Set text color to black
 	pdf.SetTextColor(0, 0, 0)
-	pdf.SetFillColor(190, 190, 190)
-	pdf.CellFormat(20, 15*4, "Facility ", "1", 0, "C", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 15, "แหล่งปล่อยก๊าซเรือนกระจก (Emission Source) เช่น ระบุ อุปกรณ์หลัก/ เครื่องจักร / กระบวนการ/กิจกรรม ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 15*4, "ที่ตั้ง/ตำแหน่ง", "1", "C", true)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*4, "ใช้ภายใน ", "1", "C", true)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*2, "จำหน่ายภายนอก", "1", "C", true)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 20, "ความสำคัญ (มีนัยสำคัญมาก หรือ น้อย) ", "1", "C", true)
-
-	pdf.CellFormat(20, 10, " ", "1", 0, "C", false, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 10, "-ไม่มี-", "1", "C", false)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 10, "", "1", "C", false)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 10, " ", "1", "C", false)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 10, "", "1", "C", false)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 10, "", "1", "C", false)
-	pdf.Ln(-1)
-
-	pdf.SetFont("THSarabunNew", "B", 14)
-	pdf.MultiCell(0, 10, "3.2.4 ระบุกิจกรรมที่เป็นแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 2 ขององค์กร", "", "L", false)
-
-	pdf.SetFillColor(190, 190, 190)
-	pdf.CellFormat(20, 15*4, "Facility ", "1", 0, "C", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 15, "แหล่งปล่อยก๊าซเรือนกระจก (Emission Source) เช่น ระบุ อุปกรณ์หลัก/ เครื่องจักร / กระบวนการ/กิจกรรม ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 15*4, "ที่ตั้ง/ตำแหน่ง", "1", "C", true)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*4, "ใช้ภายใน ", "1", "C", true)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*2, "จำหน่ายภายนอก", "1", "C", true)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 20, "ความสำคัญ (มีนัยสำคัญมาก หรือ น้อย) ", "1", "C", true)
-
-	pdf.CellFormat(20, 10, " ", "1", 0, "C", false, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 10, "-ไม่มี-", "1", "C", false)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 10, "", "1", "C", false)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 10, " ", "1", "C", false)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 10, "", "1", "C", false)
-	pdf.SetXY(x+20, y)
+	generateFlowContent(pdf, SourceTable{Report: reportData})
 
-	pdf.MultiCell(30, 10, "", "1", "C", false)
-
-	pdf.SetFont("THSarabunNew", "", 10)
-	pdf.CellFormat(45, 7.5, "หมายเหตุ :  1. มีนัยสำคัญ “มาก” หมายถึง มีปริมาณการปล่อยก๊าซเรือนกระจกตั้งแต่ร้อยละ 5 ของปริมาณการปล่อยก๊าซเรือนกระจกรวมประเภทที่ 1+2 ขององค์กร", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "2. มีนัยสำคัญ “น้อย” หมายถึง มีปริมาณการปล่อยก๊าซเรือนกระจกน้อยกว่าร้อยละ 5 ของปริมาณการปล่อยก๊าซเรือนกระจกรวมประเภทที่ 1+2 ขององค์กร", "", 2, "L", false, 0, "")
+	generateFlowContent(pdf,
+		Heading{Text: "3.2.4 ระบุกิจกรรมที่เป็นแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 2 ขององค์กร", Size: 14},
+		captions.Table("scope2-activities", "กิจกรรมที่เป็นแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 2"),
+		SourceTable{Report: reportData, Sources: reportData.sources(2), Notes: reportData.materialityNotes(reportData.sources(2))},
+	)
 
 	// 3.2.5 table
 	pdf.AddPage()
@@ -675,49 +490,18 @@ func main() {
 	//generateTableContent(pdf, data, []float64{60.0, 120.0})
 
 	//3.2.6 table
-	pdf.MultiCell(0, 10, "3.2.6   ระบุกิจกรรมที่เป็นแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 3 ขององค์กร", "", "L", false)
-	pdf.SetFillColor(190, 190, 190)
-	pdf.CellFormat(20, 15*4, "Facility ", "1", 0, "C", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 15, "แหล่งปล่อยก๊าซเรือนกระจก (Emission Source) เช่น ระบุ อุปกรณ์หลัก/ เครื่องจักร / กระบวนการ/กิจกรรม ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 15*4, "ที่ตั้ง/ตำแหน่ง", "1", "C", true)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*4, "ใช้ภายใน ", "1", "C", true)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 15*2, "จำหน่ายภายนอก", "1", "C", true)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 20, "ความสำคัญ (มีนัยสำคัญมาก หรือ น้อย) ", "1", "C", true)
-
-	pdf.CellFormat(20, 10, " ", "1", 0, "C", false, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 10, "-ไม่มี-", "1", "C", false)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 10, "", "1", "C", false)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 10, " ", "1", "C", false)
-	pdf.SetXY(x+20, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(20, 10, "", "1", "C", false)
-	pdf.SetXY(x+20, y)
-
-	pdf.MultiCell(30, 10, "", "1", "C", false)
-	pdf.SetFont("THSarabunNew", "", 10)
-	pdf.CellFormat(45, 7.5, "หมายเหตุ :  1. มีนัยสำคัญ “มาก” หมายถึง มีปริมาณการปล่อยก๊าซเรือนกระจกตั้งแต่ร้อยละ 5 ของปริมาณการปล่อยก๊าซเรือนกระจกรวมประเภทที่ 1+2 ขององค์กร", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "2. มีนัยสำคัญ “น้อย” หมายถึง มีปริมาณการปล่อยก๊าซเรือนกระจกน้อยกว่าร้อยละ 5 ของปริมาณการปล่อยก๊าซเรือนกระจกรวมประเภทที่ 1+2 ขององค์กร", "", 2, "L", false, 0, "")
+	generateFlowContent(pdf,
+		Heading{Text: "3.2.6   ระบุกิจกรรมที่เป็นแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 3 ขององค์กร", Size: 14},
+		captions.Table("scope3-activities", "กิจกรรมที่เป็นแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 3"),
+		SourceTable{Report: reportData, Sources: reportData.sources(3), Notes: reportData.materialityNotes(reportData.sources(3))},
+	)
 
 	//3.2.7 table
 	pdf.AddPage()
 	pdf.MultiCell(0, 10, "3.2.7 การกักเก็บคาร์บอน", "", "L", false)
 	pdf.SetFillColor(190, 190, 190)
 
-	x, y = pdf.GetXY()
+	x, y := pdf.GetXY()
 	pdf.MultiCell(40, 15, "รายชื่อกระบวนการ (Sink / Reservoir) ", "1", "C", true)
 	pdf.SetXY(x+40, y)
 
@@ -744,127 +528,34 @@ func main() {
 
 	//3.2.8 table
 	//pdf.AddPage()
-	pdf.MultiCell(0, 10, "3.2.8 โครงการลดก๊าซเรือนกระจก/การรับรองสิทธิพลังงานหมุนเวียน ", "", "L", false)
-	pdf.SetFillColor(190, 190, 190)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(40, 15, "ชื่อโครงการ", "1", "C", true)
-	pdf.SetXY(x+40, y)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(40, 15, "มาตรฐานที่ของรับรอง", "1", "C", true)
-	pdf.SetXY(x+40, y)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(40, 15, "ระยะเวลาคิดคาร์บอนเครดิตของโครงการ", "1", "C", true)
-	pdf.SetXY(x+40, y)
-
-	pdf.MultiCell(40, 5, "จำนวนคาร์บอนเครดิต/สิทธิพลังงานหมุนเวียน ที ่ได้รับการรับรองที ่ขายไป (TonCO2e/kWh) ", "1", "C", true)
-
-	pdf.CellFormat(40, 10, " -ไม่มี-", "1", 0, "C", false, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(40, 10, "", "1", "C", false)
-	pdf.SetXY(x+40, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(40, 10, "", "1", "C", false)
-	pdf.SetXY(x+40, y)
-
-	pdf.MultiCell(40, 10, " ", "1", "C", false)
+	generateFlowContent(pdf,
+		Heading{Text: "3.2.8 โครงการลดก๊าซเรือนกระจก/การรับรองสิทธิพลังงานหมุนเวียน", Size: 14},
+		captions.Table("credits", "โครงการลดก๊าซเรือนกระจก คาร์บอนเครดิต และสิทธิพลังงานหมุนเวียน"),
+		Table{
+			Data:         reportData.creditTable(),
+			Width:        []float64{40.0, 25.0, 35.0, 25.0, 25.0, 20.0},
+			Notes:        reportData.creditNotes(),
+			KeepTogether: true,
+		},
+		captions.Table("credit-effect", "ผลของการชดเชยต่อปริมาณการปล่อยก๊าซเรือนกระจก"),
+		Table{Data: reportData.creditEffectTable(), Width: []float64{120.0, 50.0}, KeepTogether: true},
+	)
 
 	// 4.
 	pdf.AddPage()
 	pdf.SetFont("THSarabunNew", "B", 16)
+	captions.Chapter(4)
 	pdf.CellFormat(0, 10, "4. การติดตามผล  ", "", 1, "L", false, 0, "")
 	// Set text color to black
 	pdf.SetTextColor(255, 0, 0)
 	pdf.MultiCell(0, 10, "จุดที่ตรวจวัด หมายถึง ตำแหน่งมิเตอร์ (อ้างอิงแผนผังมิเตอร์หรืออุปกรณ์ตรวจวัด ในภาคผนวก 1) หรือ จุดที่มีการบันทึกข้อมูล (อ้างอิงตามโครงสร้างระบบการจัดการคุณภาพของข้อมูลในข้อ 7.1) ", "", "L", false)
 	// Set text color to black
 	pdf.SetTextColor(0, 0, 0)
-	pdf.CellFormat(0, 10, "4.1 แหล่งปล่อยก๊าซเรือนกระจก จากขอบเขตการดำเนินงานประเภทที่ 1", "", 1, "L", false, 0, "")
-
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(35, 7.5, "", "TL", 0, "L", true, 0, "")
-	pdf.CellFormat(110, 7.5, "ข้อมูลกิจกรรม", "1", 0, "C", true, 0, "")
-	pdf.SetFont("THSarabunNew", "B", 12)
-	pdf.MultiCell(10, 7.5, "ค่า EF", "1", "L", true)
-
-	pdf.SetFont("THSarabunNew", "", 12)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 22.5, "แหล่งปล่อยก๊าซเรือนกระจก ", "BL", "C", true)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 7.5, "ลักษณะข้อมูลกิจกรรมที่ตรวจวัด ", "1", "L", true)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 11.25, "จุดที่\nตรวจวัด", "1", "L", true)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(54, 7.5, "ที่มาของข้อมูลกิจกรรม ", "1", "C", true)
-	pdf.SetXY(x, y+7.5)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 7.5, "เป็นค่าที่ได้จากการตรวจวัด", "1", "L", true)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากหลักฐานการชำระเงิน", "1", "L", true)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากการประเมินค่า", "1", "L", true)
-	pdf.SetXY(x+18, y-7.5)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(26, 11.25, "หลักฐาน/\nเอกสารอ้างอิง", "1", "L", true)
-	pdf.SetXY(x+26, y)
-
-	pdf.MultiCell(10, 7.5, "ที่มา\nของค่า EF", "1", "L", true)
-
-	pdf.CellFormat(155, 7.5, "Mobile Combustion ", "1", 0, "L", false, 0, "")
-	pdf.Ln(-1)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 28.35, "น้ำมันดีเซลรถยนต์ ", "BL", "C", false)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 11.4, "ลิตร", "1", "L", false)
-	pdf.SetXY(x, y+11.4)
-	pdf.MultiCell(15, 17.1, "บาท", "1", "L", false)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 5.7, "แผนก\nยานยนต์ ", "1", "L", false)
-	pdf.SetXY(x, y+11.4)
-	pdf.MultiCell(15, 5.7, "แผนก\nทรัพยากร\nมนุษย์ ", "1", "L", false)
-	pdf.SetXY(x+15, y)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 11.4, "", "1", "L", false)
-	pdf.SetXY(x, y+11.4)
-	pdf.MultiCell(18, 17.1, "", "1", "L", false)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 11.4, "", "1", "L", false)
-	pdf.SetXY(x, y+11.4)
-	pdf.MultiCell(18, 17.1, "", "1", "L", false)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 11.4, "", "1", "L", false)
-	pdf.SetXY(x, y+11.4)
-	pdf.MultiCell(18, 17.1, "", "1", "L", false)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(26, 5.7, "รายงานสรุป Fleet card ", "1", "L", false)
-	pdf.SetXY(x, y+11.4)
-	pdf.MultiCell(26, 4.275, "1. ยอดเบิกเงินจาก SAP \n2. ราคาน้ำมัน\nเฉลี่ยรายเดือน", "1", "L", false)
-	pdf.SetXY(x+26, y)
-
-	pdf.MultiCell(10, 9.45, "CFO\nTGO\nEF ", "1", "L", false)
-
-	pdf.Ln(-1)
-	// Set text color to blue
-	pdf.SetTextColor(0, 0, 255)
-	pdf.SetFont("THSarabunNew", "", 10)
-	pdf.CellFormat(45, 7.5, "หมายเหตุ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(1)  ข้อมูลกิจกรรมที ่ได้จากการตรวจวัด ให้ระบุรายละเอียดการสอบเทียบของอุปกรณ์ตรวจวัดไว้ในตารางที ่ 7.3  ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(2) ข้อมูลกิจกรรมที่ได้จากการประมาณค่า ให้อธิบายแนวทางในการประมาณในตารางหรืออธิบายเพิ่มเติมในภาคผนวก  ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(3) ในกรณีที่ข้อมูลกิจกรรมเป็นข้อมูลปริมาณการปล่อยก๊าซเรือนกระจกอยู่แล้ว เช่น ปริมาณการรั ่วซึมของสารทำความเย็น ให้กรอกคำว่า “ไม่ต้องใช้ค่า EF” ลงในคอลัมน์ “ที่มาของค่า EF” ", "", 2, "L", false, 0, "")
+	generateFlowContent(pdf,
+		Heading{Text: "4.1 แหล่งปล่อยก๊าซเรือนกระจก จากขอบเขตการดำเนินงานประเภทที่ 1"},
+		captions.Table("scope1-monitoring", "การติดตามผลแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 1"),
+		MonitoringTable{Report: reportData, Scope: 1, Notes: monitoringNotes(1)},
+	)
 
 	// 4.2
 	// Set text color to black
@@ -872,77 +563,11 @@ func main() {
 	pdf.SetTextColor(0, 0, 0)
 	pdf.SetFont("THSarabunNew", "B", 16)
 	pdf.AddPage()
-	pdf.CellFormat(0, 10, "4.2 แหล่งปล่อยก๊าซเรือนกระจก จากขอบเขตการดำเนินงานประเภทที่ 2 ", "", 1, "L", false, 0, "")
-
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(35, 7.5, "", "TL", 0, "L", true, 0, "")
-	pdf.CellFormat(110, 7.5, "ข้อมูลกิจกรรม", "1", 0, "C", true, 0, "")
-	pdf.SetFont("THSarabunNew", "B", 12)
-	pdf.MultiCell(10, 7.5, "ค่า EF", "1", "L", true)
-
-	pdf.SetFont("THSarabunNew", "", 12)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 22.5, "แหล่งปล่อยก๊าซเรือนกระจก ", "BL", "C", true)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 7.5, "ลักษณะข้อมูลกิจกรรมที่ตรวจวัด ", "1", "L", true)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 11.25, "จุดที่\nตรวจวัด", "1", "L", true)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(54, 7.5, "ที่มาของข้อมูลกิจกรรม ", "1", "C", true)
-	pdf.SetXY(x, y+7.5)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 7.5, "เป็นค่าที่ได้จากการตรวจวัด", "1", "L", true)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากหลักฐานการชำระเงิน", "1", "L", true)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากการประเมินค่า", "1", "L", true)
-	pdf.SetXY(x+18, y-7.5)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(26, 11.25, "หลักฐาน/\nเอกสารอ้างอิง", "1", "L", true)
-	pdf.SetXY(x+26, y)
-
-	pdf.MultiCell(10, 7.5, "ที่มา\nของค่า EF", "1", "L", true)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 28.35, "การใช้ไฟฟ้า", "BL", "C", false)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 28.35, "kWh", "1", "C", false)
-
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 14.175, "แผนก\nพลังงาน ", "1", "L", false)
-	pdf.SetXY(x+15, y)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 28.35, "", "1", "L", false)
-
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 28.35, "", "1", "L", false)
-
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 28.35, "", "1", "L", false)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(26, 4.05, "1. รายงานการ\nใช้ไฟฟ้า (จริง) rate 115 kv ประจำเดือน\nของโรงงาน  \n2. หนังสือแจ้ง\nค่าไฟฟ้าจากการ\nไฟฟ้าส่วนภูมิภาค ", "1", "L", false)
-	pdf.SetXY(x+26, y)
-
-	pdf.MultiCell(10, 9.45, "CFO\nTGO\nEF ", "1", "L", false)
-
-	// Set text color to blue
-	pdf.SetTextColor(0, 0, 255)
-	pdf.SetFont("THSarabunNew", "", 10)
-	pdf.CellFormat(45, 7.5, "หมายเหตุ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(1)  ข้อมูลกิจกรรมที ่ได้จากการตรวจวัด ให้ระบุรายละเอียดการสอบเทียบของอุปกรณ์ตรวจวัดไว้ในตารางที ่ 7.3   ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(2) ข้อมูลกิจกรรมที่ได้จากการประมาณค่า ให้อธิบายแนวทางในการประมาณในตารางหรืออธิบายเพิ่มเติมในภาคผนวก  ", "", 2, "L", false, 0, "")
+	generateFlowContent(pdf,
+		Heading{Text: "4.2 แหล่งปล่อยก๊าซเรือนกระจก จากขอบเขตการดำเนินงานประเภทที่ 2 "},
+		captions.Table("scope2-monitoring", "การติดตามผลแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 2"),
+		MonitoringTable{Report: reportData, Scope: 2, Notes: monitoringNotes(2)},
+	)
 
 	// 4.3
 	// Set text color to black
@@ -950,78 +575,11 @@ func main() {
 	pdf.SetTextColor(0, 0, 0)
 	pdf.SetFont("THSarabunNew", "B", 16)
 	pdf.AddPage()
-	pdf.CellFormat(0, 10, "4.3 แหล่งปล่อยก๊าซเรือนกระจก จากขอบเขตการด าเนินงานประเภทที ่ 3", "", 1, "L", false, 0, "")
-
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(35, 7.5, "", "TL", 0, "L", true, 0, "")
-	pdf.CellFormat(110, 7.5, "ข้อมูลกิจกรรม", "1", 0, "C", true, 0, "")
-	pdf.SetFont("THSarabunNew", "B", 12)
-	pdf.MultiCell(10, 7.5, "ค่า EF", "1", "L", true)
-
-	pdf.SetFont("THSarabunNew", "", 12)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 22.5, "แหล่งปล่อยก๊าซเรือนกระจก ", "BL", "C", true)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 7.5, "ลักษณะข้อมูลกิจกรรมที่ตรวจวัด ", "1", "L", true)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 11.25, "จุดที่\nตรวจวัด", "1", "L", true)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(54, 7.5, "ที่มาของข้อมูลกิจกรรม ", "1", "C", true)
-	pdf.SetXY(x, y+7.5)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 7.5, "เป็นค่าที่ได้จากการตรวจวัด", "1", "L", true)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากหลักฐานการชำระเงิน", "1", "L", true)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากการประเมินค่า", "1", "L", true)
-	pdf.SetXY(x+18, y-7.5)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(26, 11.25, "หลักฐาน/\nเอกสารอ้างอิง", "1", "L", true)
-	pdf.SetXY(x+26, y)
-
-	pdf.MultiCell(10, 7.5, "ที่มา\nของค่า EF", "1", "L", true)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 14.175, "1. Purchased goods \nand services ", "BL", "C", false)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 28.35, "กก.", "1", "C", false)
-
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 14.175, "แผนกคลัง\nวัตถุดิบ", "1", "L", false)
-	pdf.SetXY(x+15, y)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 28.35, "", "1", "L", false)
-
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 28.35, "", "1", "L", false)
-
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 28.35, "", "1", "L", false)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(26, 7.05, "1. ข้อมูลการ\nรับเข้าจาก\nระบบ \nSAP  ", "1", "L", false)
-	pdf.SetXY(x+26, y)
-
-	pdf.MultiCell(10, 9.45, "CFO\nTGO\nEF ", "1", "L", false)
-
-	// Set text color to blue
-	pdf.SetTextColor(0, 0, 255)
-	pdf.SetFont("THSarabunNew", "", 10)
-	pdf.CellFormat(45, 7.5, "หมายเหตุ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(1)  ข้อมูลกิจกรรมที ่ได้จากการตรวจวัด ให้ระบุรายละเอียดการสอบเทียบของอุปกรณ์ตรวจวัดไว้ในตารางที ่ 7.3   ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(2) ข้อมูลกิจกรรมที่ได้จากการประมาณค่า ให้อธิบายแนวทางในการประมาณในตารางหรืออธิบายเพิ่มเติมในภาคผนวก  ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(3) ในกรณีที ่ข้อมูลกิจกรรมเป็นข้อมูลปริมาณการปล่อยก๊าซเรือนกระจกอยู่แล้ว เช่น ปริมาณการรั ่วซึมของสารท าความเย็น ให้กรอกค าว่า “ไม่ต้องใช้ค่า EF” ลงในคอลัมน์ “ที ่มาของค่า EF” ", "", 2, "L", false, 0, "")
+	generateFlowContent(pdf,
+		Heading{Text: "4.3 แหล่งปล่อยก๊าซเรือนกระจก จากขอบเขตการด าเนินงานประเภทที ่ 3"},
+		captions.Table("scope3-monitoring", "การติดตามผลแหล่งปล่อยก๊าซเรือนกระจกประเภทที่ 3"),
+		MonitoringTable{Report: reportData, Scope: 3, Notes: monitoringNotes(3)},
+	)
 
 	// 4.4
 	// Set text color to black
@@ -1036,83 +594,20 @@ func main() {
 	pdf.CellFormat(45, 7.5, "ในกรณีที ่รำยงำนก๊ำซเรื ่อนกระจกอื ่น ๆเพิ ่มเติม หรือ รำยงำนแยกในส่วนของไบโอจินิคคำร์บอน (ถ้ำมี) ", "", 2, "L", false, 0, "")
 
 	pdf.SetTextColor(0, 0, 0)
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(35, 7.5, "", "TL", 0, "L", true, 0, "")
-	pdf.CellFormat(110, 7.5, "ข้อมูลกิจกรรม", "1", 0, "C", true, 0, "")
-	pdf.SetFont("THSarabunNew", "B", 12)
-	pdf.MultiCell(10, 7.5, "ค่า EF", "1", "L", true)
-
-	pdf.SetFont("THSarabunNew", "", 12)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 22.5, "แหล่งปล่อยก๊าซเรือนกระจก ", "BL", "C", true)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 7.5, "ลักษณะข้อมูลกิจกรรมที่ตรวจวัด ", "1", "L", true)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 11.25, "จุดที่\nตรวจวัด", "1", "L", true)
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(54, 7.5, "ที่มาของข้อมูลกิจกรรม ", "1", "C", true)
-	pdf.SetXY(x, y+7.5)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 7.5, "เป็นค่าที่ได้จากการตรวจวัด", "1", "L", true)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากหลักฐานการชำระเงิน", "1", "L", true)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากการประเมินค่า", "1", "L", true)
-	pdf.SetXY(x+18, y-7.5)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(26, 11.25, "หลักฐาน/\nเอกสารอ้างอิง", "1", "L", true)
-	pdf.SetXY(x+26, y)
-
-	pdf.MultiCell(10, 7.5, "ที่มา\nของค่า EF", "1", "L", true)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 7.5, "-ไม่มี- ", "BL", "C", false)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 7.5, "", "1", "C", false)
-
-	pdf.SetXY(x+15, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(15, 7.5, "", "1", "L", false)
-	pdf.SetXY(x+15, y)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 7.5, "", "1", "L", false)
-
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 7.5, "", "1", "L", false)
-
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(18, 7.5, "", "1", "L", false)
-	pdf.SetXY(x+18, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(26, 7.5, "", "1", "L", false)
-	pdf.SetXY(x+26, y)
-
-	pdf.MultiCell(10, 7.5, "", "1", "L", false)
-
-	// Set text color to blue
-	pdf.SetTextColor(0, 0, 255)
-	pdf.SetFont("THSarabunNew", "", 10)
-	pdf.CellFormat(45, 7.5, "หมายเหตุ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(1)  ข้อมูลกิจกรรมที ่ได้จากการตรวจวัด ให้ระบุรายละเอียดการสอบเทียบของอุปกรณ์ตรวจวัดไว้ในตารางที ่ 7.3   ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(2) ข้อมูลกิจกรรมที่ได้จากการประมาณค่า ให้อธิบายแนวทางในการประมาณในตารางหรืออธิบายเพิ่มเติมในภาคผนวก  ", "", 2, "L", false, 0, "")
-	pdf.CellFormat(45, 7.5, "(3) ในกรณีที ่ข้อมูลกิจกรรมเป็นข้อมูลปริมาณการปล่อยก๊าซเรือนกระจกอยู่แล้ว เช่น ปริมาณการรั ่วซึมของสารท าความเย็น ให้กรอกค าว่า “ไม่ต้องใช้ค่า EF” ลงในคอลัมน์ “ที ่มาของค่า EF” ", "", 2, "L", false, 0, "")
+	generateFlowContent(pdf, MonitoringTable{Report: reportData, Notes: monitoringNotes(0)})
 
 	//5.1
 	pdf.SetTextColor(0, 0, 0)
 	pdf.SetFont("THSarabunNew", "B", 16)
 	//pdf.AddPage()
+	captions.Chapter(5)
 	pdf.CellFormat(0, 10, "5. สรุปปริมาณการปล่อยก๊าซเรือนกระจก", "", 1, "L", false, 0, "")
-	pdf.CellFormat(0, 10, " 5.1 การปล่อยก๊าซเรือนกระจก จากขอบเขตการด าเนินงานประเภทที ่ 1 ", "", 1, "L", false, 0, "")
+	generateFlowContent(pdf,
+		captions.Table("scope-summary", "สรุปปริมาณการปล่อยก๊าซเรือนกระจกแยกตามขอบเขตการดำเนินงาน"),
+		Table{Data: reportData.scopeSummaryTable(), Width: []float64{70.0, 50.0, 50.0}, KeepTogether: true},
+		Spacer{Height: 10},
+	)
+	generateFlowContent(pdf, Heading{Text: " 5.1 การปล่อยก๊าซเรือนกระจก จากขอบเขตการด าเนินงานประเภทที ่ 1 "})
 
 	// Set text color to blue
 	pdf.SetTextColor(0, 0, 255)
@@ -1120,112 +615,17 @@ func main() {
 	pdf.CellFormat(45, 7.5, "เฉพำะประเภทที ่ 1 ให้แยกชนิดก๊ำซในแต่ละแหล่งปล่อย", "", 2, "L", true, 0, "")
 
 	pdf.SetTextColor(0, 0, 0)
-	pdf.SetFont("THSarabunNew", "B", 10)
-	previousX, previousY := pdf.GetXY()
-	pdf.CellFormat(35, 21.75, "แหล่งปล่อยก๊าซเรือนกระจก", "TL", 0, "L", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(110, 7, "ปริมาณการปล่อยก๊าซเรือนกระจก \n(Ton CO2e)", "1", "C", true)
-	pdf.SetXY(x+110, y)
-	pdf.MultiCell(30, 7.25, "รวมปริมาณ\nก๊าซเรือนกระจก \n(Ton CO2e) ", "1", "L", true)
-
-	pdf.SetFont("THSarabunNew", "", 10)
-	//pdf.Ln(-1)
-
-	pdf.SetXY(previousX+35, previousY+14)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "CO2 ", "1", "C", true)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "Fossil CH4", "1", "C", true)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "CH4 ", "1", "C", true)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "N2O", "1", "C", true)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "SF6", "1", "C", true)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "NF3", "1", "C", true)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "HFCs ", "1", "C", true)
-	pdf.SetXY(x+13.75, y)
-
-	pdf.MultiCell(13.75, 7.5, "PFCs ", "1", "C", true)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(35, 7.5, "Mobile Combustion ", "BL", "C", false)
-	pdf.SetXY(x+35, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "", "1", "C", false)
-
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "", "1", "L", false)
-
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "", "1", "L", false)
-
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-
-	pdf.MultiCell(30, 7.5, "", "1", "L", false)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(5, 7.5, "1", "BL", "C", false)
-	pdf.SetXY(x+5, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(30, 7.5, "น ้ามันดีเซลรถยนต์ ", "BL", "L", false)
-	pdf.SetXY(x+30, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "316.86 ", "1", "C", false)
-
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "0.53 ", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "-", "1", "L", false)
-
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "31.45", "1", "L", false)
-
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "-", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "-", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "-", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(13.75, 7.5, "-", "1", "L", false)
-	pdf.SetXY(x+13.75, y)
-
-	pdf.MultiCell(30, 7.5, "348.84 ", "1", "L", false)
+	notes := &TableNotes{Title: "หมายเหตุ"}
+	notes.Add("CO2 จากการเผาไหม้ชีวมวลและก๊าซชีวภาพไม่รวมในปริมาณการปล่อยประเภทที่ 1 และรายงานแยกในข้อ 5.4")
+	generateFlowContent(pdf,
+		captions.Table("scope1-summary", "ปริมาณการปล่อยก๊าซเรือนกระจกประเภทที่ 1 แยกตามชนิดก๊าซ"),
+		Table{
+			Data:         reportData.gasTable(),
+			Width:        []float64{35.0, 13.75, 13.75, 13.75, 13.75, 13.75, 13.75, 13.75, 13.75, 25.0},
+			Notes:        notes,
+			KeepTogether: true,
+		},
+	)
 
 	//5.2
 	pdf.SetFont("THSarabunNew", "B", 16)
@@ -1236,12 +636,15 @@ func main() {
 	//pdf.AddPage()
 	pdf.SetFont("THSarabunNew", "B", 14)
 
-	data = [][]string{
-		{"แหล่งปล่อยก๊าซเรือนกระจก ", "ปริมาณการปล่อย GHG (Ton CO2e)"},
-		{"การใช้ไฟฟ้า", "28,252.52 "},
-		{"รวมทั ้งหมด ", "28,253 "},
-	}
-	//generateTableContent(pdf, data, []float64{60.0, 120.0})
+	generateFlowContent(pdf,
+		captions.Table("scope2-summary", "ปริมาณการปล่อยก๊าซเรือนกระจกประเภทที่ 2 แบบ Location-based และ Market-based"),
+		Table{
+			Data:         reportData.scope2Table(),
+			Width:        []float64{50.0, 35.0, 15.0, 35.0, 35.0},
+			Notes:        reportData.scope2Notes(),
+			KeepTogether: true,
+		},
+	)
 
 	//5.3
 	pdf.SetFont("THSarabunNew", "B", 16)
@@ -1252,16 +655,10 @@ func main() {
 	//pdf.AddPage()
 	pdf.SetFont("THSarabunNew", "B", 14)
 
-	data = [][]string{
-		{"แหล่งปล่อยก๊าซเรือนกระจก ", "ปริมาณการปล่อย GHG (Ton CO2e)"},
-		{"1. Purchased goods and services ", "1,269,288.86"},
-		{"2. Fuel and energy related activities not included scope 1 & 2", "8,018.48"},
-		{"3. Upstream transport ", "83,586.95 "},
-		{"4. Waste generate ", "263.95"},
-		{"5. Downstream transport ", "19,068.44"},
-		{"รวมทั ้งหมด ", "1,380,227"},
-	}
-	//generateTableContent(pdf, data, []float64{60.0, 120.0})
+	generateFlowContent(pdf,
+		captions.Table("scope3-summary", "ปริมาณการปล่อยก๊าซเรือนกระจกประเภทที่ 3 แยกตามหมวดหมู่"),
+		Table{Data: reportData.scope3Summary().data(), Width: []float64{120.0, 50.0}, KeepTogether: true},
+	)
 
 	// 5.4 table
 	pdf.AddPage()
@@ -1279,65 +676,83 @@ func main() {
 		{"-ไม่มี-", " "},
 	}
 	//generateTableContent(pdf, data, []float64{60.0, 120.0})
+	generateFlowContent(pdf,
+		captions.Table("biogenic-co2", "ปริมาณ CO2 จากการเผาไหม้ชีวมวลและก๊าซชีวภาพ (Biogenic CO2)"),
+		Table{Data: reportData.biogenicTable(), Width: []float64{80.0, 50.0, 40.0}, KeepTogether: true},
+		Spacer{Height: 10},
+	)
 
 	//5.5 table
-	pdf.MultiCell(0, 10, "5.5 Carbon Intensity ", "", "L", false)
-	pdf.SetFillColor(190, 190, 190)
-	pdf.CellFormat(50, 15, "แหล่งปล่อยก๊าซเรือนกระจก  ", "1", 0, "C", true, 0, "")
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 15, "ปริมาณ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-
-	pdf.MultiCell(50, 15, "หน่วย ", "1", "C", true)
+	generateFlowContent(pdf,
+		Heading{Text: "5.5 Carbon Intensity"},
+		captions.Table("carbon-intensity", "Carbon Intensity เทียบกับปีฐาน"),
+		Table{
+			Data:         reportData.intensityTable(baseInventory),
+			Width:        []float64{50.0, 24.0, 24.0, 24.0, 24.0, 24.0},
+			Notes:        reportData.intensityNotes(),
+			KeepTogether: true,
+		},
+	)
 
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 6, "ประเภทที่ 1", "1", "C", false)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 6, "45,065.00", "1", "C", false)
-	pdf.SetXY(x+50, y)
-
-	pdf.MultiCell(50, 6, "Ton CO2e", "1", "C", false)
+	pdf.SetTextColor(0, 0, 0)
+	facilityTable := reportData.facilityTable()
+	generateFlowContent(pdf,
+		Spacer{Height: 10},
+		Paragraph{Text: "ปริมาณการปล่อยก๊าซเรือนกระจกแยกตาม Facility รวมตามแนวทาง" + reportData.Consolidation.String(), Indent: true},
+		captions.Table("facility-emissions", "ปริมาณการปล่อยก๊าซเรือนกระจกแยกตาม Facility (Ton CO2e)"),
+		Table{Data: facilityTable, Width: facilityTableWidth(len(facilityTable[0])), KeepTogether: true},
+	)
+	scopeChart := reportInventory.scopeChart()
+	scopeChart.Figure = captions.Figure("scope-share", "สัดส่วนการปล่อยก๊าซเรือนกระจกแยกตามประเภท")
+	sourceChart := reportInventory.sourceChart()
+	sourceChart.Figure = captions.Figure("source-emissions", "ปริมาณการปล่อยก๊าซเรือนกระจกแยกตามแหล่งปล่อย")
+	facilityChart := reportData.facilityChart()
+	facilityChart.Figure = captions.Figure("facility-share", "ปริมาณการปล่อยก๊าซเรือนกระจกแยกตาม Facility")
+	generateFlowContent(pdf, Spacer{Height: 10}, scopeChart, Spacer{Height: 10}, sourceChart, Spacer{Height: 10}, facilityChart, Spacer{Height: 10})
 
 	//6 / 6.1
 
 	pdf.SetTextColor(0, 0, 0)
 	pdf.SetFont("THSarabunNew", "B", 16)
 	//pdf.AddPage()
+	captions.Chapter(6)
 	pdf.CellFormat(0, 10, "6. ปีฐาน ", "", 1, "L", false, 0, "")
 	pdf.CellFormat(0, 10, " 6.1 ปีฐานที ่ใช้ในการอ้างอิง ", "", 1, "L", false, 0, "")
-	generateTextContent(pdf, true, "มกราคม ถึง ธันวาคม 2564 ซึ ่งเป็นข้อมูลที ่ได้รับการทวนสอบความถูกต้องจากผู ้ทวนสอบเรียบร้อยแล้ว โดยคลอบคลุมพื ้นที ่ รายละเอียดตามรายงานข้อ 3.1.4 ของรายงานฉบับนี ้")
+	generateTextContent(pdf, true, reportData.BaseYear.Period+" ซึ ่งเป็นข้อมูลที ่ได้รับการทวนสอบความถูกต้องจากผู ้ทวนสอบเรียบร้อยแล้ว โดยคลอบคลุมพื ้นที ่ รายละเอียดตามรายงานข้อ 3.1.4 ของรายงานฉบับนี ้")
 
 	//6.2
 	pdf.AddPage()
 	pdf.CellFormat(0, 10, "6.2 ขอบเขตการด าเนินงานในปีฐาน", "", 1, "L", false, 0, "")
 
 	//6.2 table
-	x, y = pdf.GetXY()
-	pdf.MultiCell(25, 7.5, "ขอบเขต\nการดำเนินงาน   ", "1", "C", true)
-	pdf.SetXY(x+25, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 7.5, "รายการแหล่งปล่อย\nก๊าซเรือนกระจก ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 7.5, "ปริมาณการปล่อยก๊าซเรือนกระจก\nของปีฐาน(Ton CO2e)    ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	pdf.MultiCell(30, 15, "หมายเหตุ", "1", "C", true)
-
-	x, y = pdf.GetXY()
-	pdf.MultiCell(25, 6*16, "ขอบเขตที่ 1", "1", "C", false)
-	pdf.SetXY(x+25, y)
-
-	for i := range 16 {
-		//log.Println(i)
-		x, y = pdf.GetXY()
-		pdf.MultiCell(50, 6, strconv.Itoa(i+1)+". น้ำมันดีเซลรถยนต์", "1", "C", false)
-		pdf.SetXY(x+50, y)
-		pdf.MultiCell(50, 6, "298.64 ", "1", "C", false)
-		pdf.SetXY(x+100, y)
-		pdf.MultiCell(30, 6, "", "1", "C", false)
-		pdf.SetXY(x, y+6)
-	}
+	generateFlowContent(pdf,
+		captions.Table("base-year", "ปริมาณการปล่อยก๊าซเรือนกระจกปีฐาน "+strconv.Itoa(reportData.BaseYear.Original.Year)),
+		Table{
+			Data:         reportData.BaseYear.baseYearTable(),
+			Width:        []float64{25.0, 50.0, 30.0, 30.0, 35.0},
+			Notes:        reportData.BaseYear.baseYearNotes(),
+			KeepTogether: true,
+		},
+	)
+
+	//6.3
+	notes = &TableNotes{Title: "หมายเหตุ"}
+	notes.Add("“-” หมายถึง ไม่มีข้อมูลของขอบเขตหรือแหล่งปล่อยนั้นในปีดังกล่าว")
+	comparisonChart := reportInventory.comparisonChart(baseInventory)
+	comparisonChart.Figure = captions.Figure("base-year-comparison-chart", "ปริมาณการปล่อยก๊าซเรือนกระจกปีฐานเทียบกับปีที่รายงาน")
+	generateFlowContent(pdf,
+		Spacer{Height: 10},
+		Heading{Text: "6.3 เปรียบเทียบปริมาณการปล่อยก๊าซเรือนกระจกของปีฐานกับปีที่รายงาน"},
+		captions.Table("base-year-comparison", "ปริมาณการปล่อยก๊าซเรือนกระจกปีฐาน "+strconv.Itoa(baseInventory.Year)+" และปี "+strconv.Itoa(reportInventory.Year)),
+		Table{
+			Data:         reportInventory.comparisonTable(baseInventory),
+			Width:        []float64{54.0, 30.0, 30.0, 30.0, 26.0},
+			Notes:        notes,
+			KeepTogether: true,
+		},
+		Spacer{Height: 10},
+		comparisonChart,
+	)
 
 	//7.
 	pdf.SetFont("THSarabunNew", "B", 16)
@@ -1346,44 +761,7 @@ func main() {
 	pdf.CellFormat(0, 10, " 7.1 โครงสร้างของระบบการจัดการคุณภาพของข้อมูล ", "", 1, "L", false, 0, "")
 
 	//7.1 table
-	pdf.SetFont("THSarabunNew", "", 14)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(25, 7.5, "บทบาท ", "1", "C", true)
-	pdf.SetXY(x+25, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 7.5, "ชื่อ-สกุล ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	x, y = pdf.GetXY()
-	pdf.MultiCell(50, 7.5, "ตำแหน่ง  ", "1", "C", true)
-	pdf.SetXY(x+50, y)
-	pdf.MultiCell(40, 7.5, "หน้าที่ ", "1", "C", true)
-
-	data = [][]string{
-		{"คุณ ไกรศกด กลบทอง", "ผู้จัดการโรงงาน BTG LR1"}, {"คุณ ภาคภูมิ สีแก้วสิ่ว ", "ผู้จัดการฝ่ายผลิตโรงงาน "},
-		{"คุณ บรรจบ ศฤงคารินทร์ ", "ผู้จัดการโรงงาน BTG LR3"},
-	}
-
-	firstColX, firstColY := pdf.GetXY()
-
-	pdf.SetX(pdf.GetX() + 25)
-
-	for _, name := range data {
-		//log.Println(i)
-		x, y = pdf.GetXY()
-		pdf.MultiCell(50, 6, name[0], "1", "C", false)
-		pdf.SetXY(x+50, y)
-		pdf.MultiCell(50, 6, name[1], "1", "C", false)
-		pdf.SetXY(x+100, y)
-		pdf.MultiCell(30, 6, "", "", "C", false)
-		pdf.SetXY(x, y+6)
-
-	}
-	pdf.SetXY(x+100, firstColY)
-	pdf.MultiCell(40, (6 * float64(len(data)) / 2), "กำหนดนโยบายในการ\nบริหารงานขององค์กร ", "1", "C", false)
-
-	pdf.SetXY(firstColX, firstColY)
-	pdf.MultiCell(25, (6 * float64(len(data)) / 2), "ผู้จัดการ\nโรงงาน ", "1", "C", false)
-	pdf.SetXY(x+25, y)
+	generateRolesTable(pdf, reportOrganisation)
 
 	//7.2
 	//1.
@@ -1401,7 +779,7 @@ func main() {
 		{"ยอดเบิกจาก SAP (เบิกจาก Station ภายในโรงงาน) ", "พนักงานจ่ายน้ำมัน \nความถี่ : ทุกครั้งที่มีการเติม ", "เจ้าหน้าที่สโตร์\nความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 		{"Fleet Card", "เจ้าหน้าที่แผนกยานยนต์ \nส่วนกลาง เบทาโกร \nความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่แผนกบัญชี\nส่วนกลาง เบทาโกร \nความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//1.2
@@ -1414,7 +792,7 @@ func main() {
 		{"รายงานสรุป Fleet Card ", "เจ้าหน้าที่แผนกยานยนต์ \nความถี่ : เดือนละ 1 ครั้ง ", "ผู้จัดการแผนกยานยนต์ \nความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 		{"1.ใบรายงานการใช้น้ำมัน\nและแก๊ส/ใบเสร็จรับเงิน\n2.ราคาน้ำมันเฉลี่ยรายเดือน\n3.SAP", "เจ้าหน้าที่แผนกทรัพยากรมนุษย์ ความถี่ : เดือนละ 1 ครั้ง", "ผู้จัดการแผนกทรัพยากรมนุษย์ ความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//1.3
@@ -1426,7 +804,7 @@ func main() {
 		{"หลักฐานอ้างอิง ", "การบันทึกข้อมูล", "การตรวจสอบข้อมูล", "การรวบรวมข้อมูลคำนวณ CFO"},
 		{"1.SAP\n2.ราคา NVG เฉลี่ยต่อเดือน", "เจ้าหน้าที่แผนกทรัพยากรมนุษย์ ความถี่ : เดือนละ 1 ครั้ง", "ผู้จัดการแผนกทรัพยากรมนุษย์ ความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//1.4
@@ -1438,7 +816,7 @@ func main() {
 		{"หลักฐานอ้างอิง ", "การบันทึกข้อมูล", "การตรวจสอบข้อมูล", "การรวบรวมข้อมูลคำนวณ CFO"},
 		{"ยอดเบิกจากระบบ SAP", "ผู้จัดการแผนกคลังสินค้า ความถี่ : ทุกครั้งที่มีการเบิก", " ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//2.
@@ -1452,7 +830,7 @@ func main() {
 		{"หลักฐานอ้างอิง ", "การบันทึกข้อมูล", "การตรวจสอบข้อมูล", "การรวบรวมข้อมูลคำนวณ CFO"},
 		{"ยอดเบิกจาก SAP (เบิกจาก Station ภายในโรงงาน) ", "พนักงานจ่ายน้ำมัน \nความถี่ : ทุกครั้งที่มีการเติม ", "เจ้าหน้าที่สโตร์\nความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//2.2
@@ -1464,7 +842,7 @@ func main() {
 		{"หลักฐานอ้างอิง ", "การบันทึกข้อมูล", "การตรวจสอบข้อมูล", "การรวบรวมข้อมูลคำนวณ CFO"},
 		{"SAP", "เจ้าหน้าที่แผนกทรัพยากรมนุษย์ ความถี่ : เดือนละ 1 ครั้ง", "ผู้จัดการแผนกทรัพยากรมนุษย์ ความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//2.3
@@ -1478,7 +856,7 @@ func main() {
 		{"ยอดเบิกจาก SAP (Boiler)", "เจ้าหน้าที่ซ่อมบำรุง (ดูแล Boiler) \nความถี่ : เดือนละ 1 ครั้ง", "เจ้าหน้าที่ธุรการผลิต \nความถี่ : เดือนละ 1 ครั้ง ", "เจ้าหน้าที่สิ่งแวดล้อม \nความถี่ : เดือนละ 1 ครั้ง"},
 		{"ยอดเบิกจาก SAP (อบข้าวโพด)", "เจ้าหน้าที่ silo ความถี่ : ทุกครั้งที่มีการเบิก", " ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//2.4
@@ -1490,7 +868,7 @@ func main() {
 		{"หลักฐานอ้างอิง ", "การบันทึกข้อมูล", "การตรวจสอบข้อมูล", "การรวบรวมข้อมูลคำนวณ CFO"},
 		{"ยอดเบิกจากระบบ SAP LR1,LR2,LR3 ", "ผู้จัดการแผนกคลังสินค้า ความถี่ : ทุกครั้งที่มีการเบิก", " ", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//2.5
@@ -1502,7 +880,7 @@ func main() {
 		{"หลักฐานอ้างอิง ", "การบันทึกข้อมูล", "การตรวจสอบข้อมูล", "การรวบรวมข้อมูลคำนวณ CFO"},
 		{"1.ยอดเบิกจากระบบ SAP\n2.ค่าความร้อนจาก Supplier", "เจ้าหน้าที่สโตร์ ความถี่ : เดือนละ 1 ครั้ง", "ผู้จัดการผลิต \n ความถี่ : เดือนละ 1 ครั้ง", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	//generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	//7.2
 	//2.6
@@ -1514,46 +892,158 @@ func main() {
 		{"หลักฐานอ้างอิง ", "การบันทึกข้อมูล", "การตรวจสอบข้อมูล", "การรวบรวมข้อมูลคำนวณ CFO"},
 		{"ยอดเบิกใช้จากระบบ SAP LR3", "เจ้าหน้าที่สโตร์ ความถี่ : ทุกครั้งที่มีการเบิก", "ผู้จัดการผลิต \n ความถี่ : เดือนละ 1 ครั้ง", "เจ้าหน้าที่สิ่งแวดล้อม\nความถี่ : เดือนละ 1 ครั้ง"},
 	}
-	generateTableContent(pdf, data, []float64{40.0, 40.0, 40.0, 40.0})
+	generateFlowContent(pdf, FlowDiagram{Data: data})
 
 	// ภาคผนวก
 	pdf.AddPage()
 	// Title
-	pdf.SetFont("THSarabunNew", "B", 16)
-	pdf.CellFormat(0, 10, "ภาคผนวก", "", 1, "C", false, 0, "")
+	generateFlowContent(pdf,
+		Heading{Text: "ภาคผนวก", Align: "C"},
+		// Content
+		// paragraph 1
+		Paragraph{Text: `ตามข้อก าหนดของ อบก. ก าหนดให้องค์กรมีกระบวนการชี ้บ่งแหล่งปล่อยก๊าซเรือนกระจกทางอ้อมอื ่นๆ (ประเภทที ่ 3) ที ่จะน ามารวมในบัญชีรายการก๊าซเรือนกระจก โดยให้ความส าคัญของแหล่งการปล่อยก๊าซเรือนกระจกตามหลักเกณฑ์ดังต่อไปนี ้ `, Indent: true},
+		// criteria
+		List{
+			Style: ListDash,
+			Items: []ListItem{
+				{Text: `ขนาด (Magnitude): เป็นกิจกรรมการปล่อยหรือดูดกลับก๊าซเรือนกระจกทางอ้อมซึ่่งถูกสันนิษฐานว่ามีปริมาณการปล่อยหรือดูดกลับก๊าซเรือนกระจกในปริมาณมากอย่างมีนัยส าคัญ`},
+				{Text: `ระดับของแรงจูงใจ(Level of influence): เป็นกิจกรรมการปล่อยหรือดูดกลับก๊าซเรือนกระจกที ่องค์กรมีความสามารถในการตรวจติดตามและลดปริมาณการปล่อยหรือดูดกลับก๊าซเรือนกระจกจากกิจกรรมนั ้น(ตัวอย่างเช่นเป็นกิจจกรรมที ่เกี ่ยวข้องกับการประเมินประสิทธิภาพพลังงาน การออกแบบ ชิงนิเวศเศรษฐกิจ, เกี ่ยวข้องกับข้อตกลงที ่มีกับลูกค้า, เกี ่ยวข้องกับข้อก าหนดขอบเขตงานจากผู ้ว่าจ้าง)`},
+				{Text: `ความเสี ่ยงหรือโอกาส (Risk or opportunity): เป็นกิจกรรมการปล่อยหรือดูดกลับก๊าซเรือนกระจกทางอ้อมซึ ่งมีส่วนท าให้องค์กรได้รับความเสี ่ยง (ตัวอย่างของความเสี ่ยงที ่มีความเชื ่อมโยงกับการเปลี ่ยนแปลงสภาพภูมิอากาศ เช่น ความเสี ่ยงทางด้านการเงิน, ความเสี ่ยงทางด้านกฎระเบียบข้อบังคับ, ความเสี ่ยงตลอดห่วงโซ่อุปทาน, ความเสี ่ยงเกี ่ยวกับสินค้าและลูกค้า, ความเสี ่ยงเกี ่ยวกับการด าเนินคดี และ ความเสี ่ยงด้านชื ่อเสียง) หรือได้รับโอกาสต่างๆ ทางธุรกิจ (เช่น การเข้าสู ่ช่องทางตลาดใหม่ การเข้าสู ่ระบบธุรกิจในรูปแบบใหม่)`},
+				{Text: `เป็นการจัดจ้างบุคคลหรือหน่วยงานภายนอก (Outsourcing): เป็นกิจกรรมการปล่อยและดูดกลับก๊าซเรือนกระจกทางอ้อมที ่เกิดจากการจัดจ้างบุคคลหรือหน่วยงานภายนอกเข้ามาด าเนินกิจกรรมที ่ถือว่าเป็นกิจกรรมหลักในการด าเนินธุรกิจขององค์กร`},
+				{Text: `เป็นการส่งเสริมการมีส่วนร่วมของพนักงาน (Employee engagement): เป็นกิจกรรมการปล่อยก๊าซเรือนกระจกทางอ้อมที ่สามารถส่งเสริมให้เกิดการกระตุ ้นให้พนักงานมีส่วนร่วมในการลดการปล่อยก๊าซเรือนกระจก ผ่านการลดการใช้พลังงาน หรือการท างานร่วมกันเป็นทีมภายใต้หลักคิดที ่เกี ่ยวข้องกับการเปลี ่ยนแปลงสภาพภูมิอากาศ (เช่น การสร้างแรงจูงใจในการอนุรักษ์พลังงาน, การเดินทางโดยใช้รถร่วมกัน, การประเมินราคาคาร์บอนภายในองค์กร เป็นต้น)`},
+			},
+		},
+	)
+
+	return pdf
+}
 
-	// Content
-	// paragraph 1
-	pdf.SetFont("THSarabunNew", "B", 14)
-	content = `ตามข้อก าหนดของ อบก. ก าหนดให้องค์กรมีกระบวนการชี ้บ่งแหล่งปล่อยก๊าซเรือนกระจกทางอ้อมอื ่นๆ (ประเภทที ่ 3) ที ่จะน ามารวมในบัญชีรายการก๊าซเรือนกระจก โดยให้ความส าคัญของแหล่งการปล่อยก๊าซเรือนกระจกตามหลักเกณฑ์ดังต่อไปนี ้ `
-	generateTextContent(pdf, true, content)
+// makePDFA converts data to PDF/A at level, such as "2b". It prints the
+// problems the self-check finds in the result and fails when there are any,
+// rather than return a file that claims a conformance it does not have.
+func makePDFA(data []byte, level string) ([]byte, error) {
+	part, err := parsePDFAPart(level)
+	if err != nil {
+		return nil, err
+	}
+	data, err = convertToPDFA(data, part)
+	if err != nil {
+		return nil, err
+	}
+	if problems := checkPDFA(data, part); len(problems) > 0 {
+		fmt.Printf("PDF/A-%s check found %d problems:\n", strings.ToUpper(level), len(problems))
+		for _, problem := range problems {
+			fmt.Println(" -", problem)
+		}
+		return nil, fmt.Errorf("the report does not conform to PDF/A-%s", strings.ToUpper(level))
+	}
+	fmt.Printf("PDF/A-%s check passed\n", strings.ToUpper(level))
+	return data, nil
+}
 
-	// paragraph 2
-	content = `- ขนาด (Magnitude): เป็นกิจกรรมการปล่อยหรือดูดกลับก๊าซเรือนกระจกทางอ้อมซึ่่งถูกสันนิษฐานว่ามีปริมาณการปล่อยหรือดูดกลับก๊าซเรือนกระจกในปริมาณมากอย่างมีนัยส าคัญ `
-	generateTextContent(pdf, true, content)
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(runVerify(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
 
-	// paragraph 3
-	content = `- ระดับของแรงจูงใจ(Level of influence): เป็นกิจกรรมการปล่อยหรือดูดกลับก๊าซเรือนกระจกที ่องค์กรมีความสามารถในการตรวจติดตามและลดปริมาณการปล่อยหรือดูดกลับก๊าซเรือนกระจกจากกิจกรรมนั ้น(ตัวอย่างเช่นเป็นกิจจกรรมที ่เกี ่ยวข้องกับการประเมินประสิทธิภาพพลังงาน การออกแบบ ชิงนิเวศเศรษฐกิจ, เกี ่ยวข้องกับข้อตกลงที ่มีกับลูกค้า, เกี ่ยวข้องกับข้อก าหนดขอบเขตงานจากผู ้ว่าจ้าง)`
-	generateTextContent(pdf, true, content)
+	pdfa := flag.String("pdfa", "", "write PDF/A at level `1b or 2b`")
+	var protection Protection
+	flag.StringVar(&protection.UserPassword, "user-password", "", "encrypt the PDF with the `password` needed to open it")
+	flag.StringVar(&protection.OwnerPassword, "owner-password", "", "encrypt the PDF with the `password` that gives full access")
+	flag.StringVar(&protection.Allow, "allow", "", "encrypt the PDF and allow only the `actions` print, modify, copy and annot-forms, or none")
+	signCert := flag.String("sign-cert", "", "sign the PDF with the certificates in the PEM or PKCS#12 (.p12, .pfx) `file`")
+	signKey := flag.String("sign-key", "", "read the signing key from the PEM `file`; defaults to -sign-cert")
+	signPassword := flag.String("sign-password", "", "the `password` of a PKCS#12 -sign-cert file")
+	signReason := flag.String("sign-reason", "ทวนสอบและรับรอง "+reportInfo.Title, "the `reason` given in the signature")
+	signVisible := flag.Bool("sign-visible", false, "add a sign-off page with a visible signature")
+	var mark Watermark
+	flag.StringVar(&mark.Text, "watermark", "", "mark the pages with `text` such as \"ฉบับร่าง / DRAFT\"")
+	flag.StringVar(&mark.Revision, "watermark-revision", "", "the draft `revision` shown under the watermark text")
+	flag.StringVar(&mark.Image, "watermark-image", "", "mark the pages with the image `file` instead of text")
+	flag.BoolVar(&mark.Above, "watermark-above", false, "draw the watermark over the page content instead of behind it")
+	watermarkPages := flag.String("watermark-pages", "", "mark only the `pages` such as 1,3-5,10-; the cover is page 1")
+	force := flag.Bool("force", false, "render a report that fails validation")
+	flag.Parse()
+	if *pdfa != "" && protection.enabled() {
+		fmt.Println("Error: PDF/A does not allow encryption")
+		os.Exit(1)
+	}
+	if _, err := parsePermissions(protection.Allow); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	var watermark *Watermark
+	if mark.Text != "" || mark.Image != "" {
+		var err error
+		if mark.Pages, err = parsePageRanges(*watermarkPages); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		mark.Stamp = time.Now().Format("2006-01-02 15:04")
+		watermark = &mark
+	}
+	var signer *Signer
+	if *signCert != "" {
+		if protection.enabled() {
+			fmt.Println("Error: signing encrypted documents is not supported")
+			os.Exit(1)
+		}
+		var err error
+		if signer, err = loadSigner(*signCert, *signKey, *signPassword); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		signer.Reason = *signReason
+	}
 
-	// paragraph 4
-	content = `- ความเสี ่ยงหรือโอกาส (Risk or opportunity): เป็นกิจกรรมการปล่อยหรือดูดกลับก๊าซเรือนกระจกทางอ้อมซึ ่งมีส่วนท าให้องค์กรได้รับความเสี ่ยง (ตัวอย่างของความเสี ่ยงที ่มีความเชื ่อมโยงกับการเปลี ่ยนแปลงสภาพภูมิอากาศ เช่น ความเสี ่ยงทางด้านการเงิน, ความเสี ่ยงทางด้านกฎระเบียบข้อบังคับ, 
-ความเสี ่ยงตลอดห่วงโซ่อุปทาน, ความเสี ่ยงเกี ่ยวกับสินค้าและลูกค้า, ความเสี ่ยงเกี ่ยวกับการด าเนินคดี และ ความเสี ่ยงด้านชื ่อเสียง) หรือได้รับโอกาสต่างๆ ทางธุรกิจ (เช่น การเข้าสู ่ช่องทางตลาดใหม่ การเข้าสู ่ระบบธุรกิจในรูปแบบใหม่) `
-	generateTextContent(pdf, true, content)
+	// Invalid reports are flagged, and only rendered when forced.
+	if findings := reportData.validate(); len(findings) > 0 {
+		printFindings(findings)
+		if !*force && hasErrors(findings) {
+			fmt.Println("Error: the report is invalid")
+			os.Exit(1)
+		}
+	}
 
-	// paragraph 5
-	content = `- เป็นการจัดจ้างบุคคลหรือหน่วยงานภายนอก (Outsourcing): เป็นกิจกรรมการปล่อยและดูดกลับก๊าซเรือนกระจกทางอ้อมที ่เกิดจากการจัดจ้างบุคคลหรือหน่วยงานภายนอกเข้ามาด าเนินกิจกรรมที ่ถือว่าเป็นกิจกรรมหลักในการด าเนินธุรกิจขององค์กร  `
-	generateTextContent(pdf, true, content)
+	// Lay the report out again until the caption numbers and pages settle.
+	captions := &Captions{PerChapter: true}
+	pdf := generateReport(captions, watermark)
+	for pass := 1; pass < 4 && captions.changed(); pass++ {
+		pdf = generateReport(captions, watermark)
+	}
+	if err := captions.Err(); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
 
-	// paragraph 6
-	content = `- เป็นการส่งเสริมการมีส่วนร่วมของพนักงาน (Employee engagement): เป็นกิจกรรมการปล่อยก๊าซเรือนกระจกทางอ้อมที ่สามารถส่งเสริมให้เกิดการกระตุ ้นให้พนักงานมีส่วนร่วมในการลดการปล่อยก๊าซเรือนกระจก ผ่านการลดการใช้พลังงาน หรือการท างานร่วมกันเป็นทีมภายใต้หลักคิดที ่เกี ่ยวข้องกับการเปลี ่ยนแปลงสภาพภูมิอากาศ (เช่น การสร้างแรงจูงใจในการอนุรักษ์พลังงาน, การเดินทางโดยใช้รถร่วมกัน, การประเมินราคาคาร์บอนภายในองค์กร เป็นต้น)  `
-	generateTextContent(pdf, true, content)
+	signedAt := time.Now()
+	var field *SignatureField
+	if signer != nil && *signVisible {
+		field = addSignOffPage(pdf, reportInfo, signer, signedAt)
+	}
 
 	// Save the PDF to a file
-	err := pdf.OutputFileAndClose("output.pdf")
+	err := protection.apply(pdf)
+	var buf bytes.Buffer
+	if err == nil {
+		err = pdf.Output(&buf)
+	}
+	data := buf.Bytes()
+	if err == nil && *pdfa != "" {
+		data, err = makePDFA(data, *pdfa)
+	}
+	if err == nil && signer != nil {
+		data, err = signer.sign(data, field, signedAt)
+	}
+	if err == nil {
+		err = os.WriteFile("output.pdf", data, 0644)
+	}
 	if err != nil {
 		fmt.Println("Error saving PDF:", err)
-		return
+		os.Exit(1)
 	}
 
 	fmt.Println("PDF created successfully")
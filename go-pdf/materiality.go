@@ -0,0 +1,228 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// The significance of an emission source in the tables of section 3.2.
+const (
+	significant    = "มาก"
+	notSignificant = "น้อย"
+)
+
+// materialityPercent formats the materiality threshold as a percentage,
+// e.g. "5".
+func (r Report) materialityPercent() string {
+	return formatPercent(r.Materiality)
+}
+
+// inventorySource returns the emissions of the inventory source of scope
+// that matches name.
+func (r Report) inventorySource(scope int, name string) (EmissionSource, bool) {
+	for _, source := range r.inventory().Sources {
+		if source.Scope == scope && sourceKey(source.Name) == sourceKey(name) {
+			return source, true
+		}
+	}
+	return EmissionSource{}, false
+}
+
+// share returns the emissions of source as a fraction of the scope 1 and 2
+// total, which is what the materiality threshold applies to.
+func (r Report) share(source ScopeSource) (float64, bool) {
+	emissions, ok := r.inventorySource(source.Scope, source.Name)
+	inv := r.inventory()
+	base := inv.ScopeTotal(1) + inv.ScopeTotal(2)
+	if !ok || base <= 0 {
+		return 0, false
+	}
+	return emissions.Emissions / base, true
+}
+
+// computedSignificance returns "มาก" when source reaches the materiality
+// threshold and "น้อย" when it does not, or false when the inventory has
+// no emissions for it.
+func (r Report) computedSignificance(source ScopeSource) (string, bool) {
+	share, ok := r.share(source)
+	if !ok {
+		return "", false
+	}
+	if share >= r.Materiality {
+		return significant, true
+	}
+	return notSignificant, true
+}
+
+// significance returns the significance shown for source: the override when
+// there is one, otherwise the computed one.
+func (r Report) significance(source ScopeSource) string {
+	if source.Override != "" {
+		return source.Override
+	}
+	level, _ := r.computedSignificance(source)
+	return level
+}
+
+// materialityNotes returns the notes under the table of sources: the
+// meaning of "มาก" and "น้อย" at the materiality threshold, and the
+// justification of each overridden significance, whose cell is marked "*".
+func (r Report) materialityNotes(sources []ScopeSource) *TableNotes {
+	percent := r.materialityPercent()
+	notes := &TableNotes{Title: "หมายเหตุ :"}
+	notes.Add("มีนัยสำคัญ “มาก” หมายถึง มีปริมาณการปล่อยก๊าซเรือนกระจกตั้งแต่ร้อยละ " + percent + " ของปริมาณการปล่อยก๊าซเรือนกระจกรวมประเภทที่ 1+2 ขององค์กร")
+	notes.Add("มีนัยสำคัญ “น้อย” หมายถึง มีปริมาณการปล่อยก๊าซเรือนกระจกน้อยกว่าร้อยละ " + percent + " ของปริมาณการปล่อยก๊าซเรือนกระจกรวมประเภทที่ 1+2 ขององค์กร")
+	for _, source := range sources {
+		if source.Override == "" {
+			continue
+		}
+		text := "* " + strings.TrimSpace(source.Name) + " กำหนดความสำคัญเป็น “" + source.Override + "”"
+		if share, ok := r.share(source); ok {
+			level, _ := r.computedSignificance(source)
+			text += " (คำนวณได้ร้อยละ " + formatNumber(100*share, 2) + " หรือ “" + level + "”)"
+		}
+		notes.Add(text + " เนื่องจาก " + source.Justification)
+	}
+	return notes
+}
+
+// SourceTable is a table of sources of section 3.2 with its notes, drawn
+// with generateSourceHeader and generateSourceRows.
+type SourceTable struct {
+	Report  Report
+	Sources []ScopeSource
+	Notes   *TableNotes
+}
+
+const sourceHeaderHeight = 60.0
+
+func (t SourceTable) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	h := sourceHeaderHeight + t.Notes.height(pdf, w)
+	if len(t.Sources) == 0 {
+		return h + 10
+	}
+	categories := map[string]bool{}
+	for _, source := range t.Sources {
+		categories[source.Category] = true
+	}
+	return h + 10*float64(len(categories)) + 15*float64(len(t.Sources))
+}
+
+// leadHeight keeps the header with the first category and source.
+func (t SourceTable) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	if len(t.Sources) < 2 {
+		return t.height(pdf, w)
+	}
+	return sourceHeaderHeight + 10 + 15
+}
+
+func (t SourceTable) render(pdf *gofpdf.Fpdf, w float64) {
+	generateSourceHeader(pdf)
+	generateSourceRows(pdf, t.Report, t.Sources, t.Notes)
+}
+
+// generateSourceHeader draws the header of a table of sources of section
+// 3.2 at the left margin.
+func generateSourceHeader(pdf *gofpdf.Fpdf) {
+	left, _, _, _ := pdf.GetMargins()
+	pdf.SetX(left)
+	pdf.SetFont("THSarabunNew", "B", 14)
+	pdf.SetFillColor(190, 190, 190)
+	pdf.CellFormat(20, 15*4, "Facility ", "1", 0, "C", true, 0, "")
+	cells := []struct {
+		w, lineHeight float64
+		text          string
+	}{
+		{50, 15, "แหล่งปล่อยก๊าซเรือนกระจก (Emission Source) เช่น ระบุ อุปกรณ์หลัก/ เครื่องจักร / กระบวนการ/กิจกรรม "},
+		{30, 15 * 4, "ที่ตั้ง/ตำแหน่ง"},
+		{20, 15 * 4, "ใช้ภายใน "},
+		{20, 15 * 2, "จำหน่ายภายนอก"},
+	}
+	for _, cell := range cells {
+		x, y := pdf.GetXY()
+		pdf.MultiCell(cell.w, cell.lineHeight, cell.text, "1", "C", true)
+		pdf.SetXY(x+cell.w, y)
+	}
+	pdf.MultiCell(30, 20, "ความสำคัญ (มีนัยสำคัญมาก หรือ น้อย) ", "1", "C", true)
+}
+
+// generateSourceRows draws the rows of a table of sources of section 3.2
+// under the header drawn by generateSourceHeader: the sources grouped by category, or
+// a "-ไม่มี-" row when there are none, followed by notes. The last row is
+// kept on the page of the notes.
+func generateSourceRows(pdf *gofpdf.Fpdf, r Report, sources []ScopeSource, notes *TableNotes) {
+	var categories []string
+	bySource := map[string][]ScopeSource{}
+	for _, source := range sources {
+		if _, ok := bySource[source.Category]; !ok {
+			categories = append(categories, source.Category)
+		}
+		bySource[source.Category] = append(bySource[source.Category], source)
+	}
+
+	// Rows that do not fit go to the next page as a whole.
+	top, bottom := contentBounds(pdf)
+	notesHeight := notes.height(pdf, contentWidth(pdf))
+	keep := func(h float64, last bool) {
+		if last {
+			h += notesHeight
+		}
+		if pdf.GetY()+h > bottom && pdf.GetY() > top {
+			pdf.AddPage()
+			pdf.SetY(top)
+		}
+	}
+
+	pdf.SetFont("THSarabunNew", "B", 14)
+	pdf.SetFillColor(255, 255, 255)
+	if len(categories) == 0 {
+		keep(10, true)
+		cells := []struct {
+			w    float64
+			text string
+		}{{20, " "}, {50, "-ไม่มี-"}, {30, ""}, {20, " "}, {20, ""}, {30, ""}}
+		for _, cell := range cells {
+			x, y := pdf.GetXY()
+			pdf.MultiCell(cell.w, 10, cell.text, "1", "C", false)
+			pdf.SetXY(x+cell.w, y)
+		}
+		pdf.Ln(10)
+		generateTableNotes(pdf, notes)
+		return
+	}
+
+	n := 0
+	for _, category := range categories {
+		keep(10+15, n+1 == len(sources))
+		pdf.SetFont("THSarabunNew", "B", 14)
+		pdf.CellFormat(170, 10, category, "1", 0, "L", true, 0, "")
+		pdf.Ln(-1)
+		for _, source := range bySource[category] {
+			n++
+			keep(15, n == len(sources))
+			level := r.significance(source)
+			if source.Override != "" {
+				level += "*"
+			}
+			cells := []struct {
+				w    float64
+				text string
+			}{
+				{20, source.Facility},
+				{50, source.Name},
+				{30, source.Location},
+				{20, source.Internal},
+				{20, source.External},
+				{30, level},
+			}
+			for _, cell := range cells {
+				x, y := pdf.GetXY()
+				pdf.MultiCell(cell.w, 15, cell.text, "1", "C", true)
+				pdf.SetXY(x+cell.w, y)
+			}
+			pdf.Ln(15)
+		}
+	}
+	generateTableNotes(pdf, notes)
+}
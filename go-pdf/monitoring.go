@@ -0,0 +1,282 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// MonitoringTable is a table of section 4: how the activity data of the
+// sources of Scope is monitored, from Report.Monitoring, with its notes.
+// Scope 0 is the table of the sources reported separately in 4.4, which
+// has none. Sources are grouped by their category in section 3.2.
+type MonitoringTable struct {
+	Report Report
+	Scope  int
+	Notes  *TableNotes
+}
+
+const (
+	monitoringFontSize     = 12.0
+	monitoringLineHeight   = 5.7
+	monitoringHeaderHeight = 30.0
+	monitoringBarHeight    = 7.5
+)
+
+// monitoringWidth are the widths of the columns of a MonitoringTable: the
+// source, the activity data, the point it is measured at, the three origins,
+// the evidence and the source of the EF.
+var monitoringWidth = []float64{35, 15, 15, 18, 18, 18, 26, 10}
+
+// monitoringOrigins are the origins in the order of their columns.
+var monitoringOrigins = []string{OriginMeasured, OriginPayment, OriginEstimated}
+
+// monitoringGroup is the entries of a category of a MonitoringTable.
+type monitoringGroup struct {
+	category string
+	entries  []Monitoring
+}
+
+// groups returns the entries of the table grouped by the category of their
+// source, in the order the categories first appear.
+func (t MonitoringTable) groups() []monitoringGroup {
+	var groups []monitoringGroup
+	for _, m := range t.Report.Monitoring {
+		if m.Scope != t.Scope {
+			continue
+		}
+		category := ""
+		for _, source := range t.Report.sources(m.Scope) {
+			if sourceKey(source.Name) == sourceKey(m.Source) {
+				category = source.Category
+				break
+			}
+		}
+		if len(groups) == 0 || groups[len(groups)-1].category != category {
+			groups = append(groups, monitoringGroup{category: category})
+		}
+		groups[len(groups)-1].entries = append(groups[len(groups)-1].entries, m)
+	}
+	return groups
+}
+
+// monitoringRows returns the heights of the lines of m: one for each
+// activity data with its measuring point and evidence. The last line is
+// taller when the source or its EF need more room.
+func monitoringRows(pdf *gofpdf.Fpdf, m Monitoring) []float64 {
+	pdf.SetFont("THSarabunNew", "", monitoringFontSize)
+	lines := func(items []string, i, col int) int {
+		if i >= len(items) {
+			return 1
+		}
+		return len(splitLines(pdf, items[i], monitoringWidth[col]))
+	}
+	n := max(1, len(m.Units), len(m.Points), len(m.Evidence))
+	heights := make([]float64, n)
+	total := 0.0
+	for i := range heights {
+		heights[i] = monitoringLineHeight * float64(max(2, lines(m.Units, i, 1), lines(m.Points, i, 2), lines(m.Evidence, i, 6)))
+		total += heights[i]
+	}
+	need := monitoringLineHeight * float64(max(lines([]string{m.Source}, 0, 0), lines([]string{m.EFSource}, 0, 7)))
+	if need > total {
+		heights[n-1] += need - total
+	}
+	return heights
+}
+
+func (t MonitoringTable) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	h := monitoringHeaderHeight + t.Notes.height(pdf, w)
+	groups := t.groups()
+	if len(groups) == 0 {
+		return h + monitoringBarHeight
+	}
+	for _, group := range groups {
+		if group.category != "" {
+			h += monitoringBarHeight
+		}
+		for _, m := range group.entries {
+			h += sum(monitoringRows(pdf, m))
+		}
+	}
+	return h
+}
+
+// leadHeight keeps the header with the first source.
+func (t MonitoringTable) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	groups := t.groups()
+	if len(groups) == 0 || len(groups) == 1 && len(groups[0].entries) == 1 {
+		return t.height(pdf, w)
+	}
+	h := monitoringHeaderHeight + sum(monitoringRows(pdf, groups[0].entries[0]))
+	if groups[0].category != "" {
+		h += monitoringBarHeight
+	}
+	return h
+}
+
+func (t MonitoringTable) render(pdf *gofpdf.Fpdf, w float64) {
+	top, bottom := contentBounds(pdf)
+	left, _, _, _ := pdf.GetMargins()
+	notesHeight := t.Notes.height(pdf, w)
+	tableWidth := sum(monitoringWidth)
+
+	// Sources that do not fit go to the next page as a whole; the last one
+	// stays with the notes.
+	keep := func(h float64, last bool) {
+		if last {
+			h += notesHeight
+		}
+		if pdf.GetY()+h > bottom && pdf.GetY() > top {
+			pdf.AddPage()
+			pdf.SetY(top)
+		}
+	}
+
+	generateMonitoringHeader(pdf, t.Notes)
+	pdf.SetFont("THSarabunNew", "", monitoringFontSize)
+	groups := t.groups()
+	if len(groups) == 0 {
+		keep(monitoringBarHeight, true)
+		pdf.SetX(left)
+		pdf.CellFormat(monitoringWidth[0], monitoringBarHeight, "-ไม่มี- ", "1", 0, "C", false, 0, "")
+		for _, cw := range monitoringWidth[1:] {
+			pdf.CellFormat(cw, monitoringBarHeight, "", "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	for g, group := range groups {
+		for i, m := range group.entries {
+			rows := monitoringRows(pdf, m)
+			h := sum(rows)
+			if i == 0 && group.category != "" {
+				h += monitoringBarHeight
+			}
+			keep(h, g == len(groups)-1 && i == len(group.entries)-1)
+			if i == 0 && group.category != "" {
+				pdf.SetX(left)
+				pdf.CellFormat(tableWidth, monitoringBarHeight, group.category, "1", 1, "L", false, 0, "")
+			}
+			drawMonitoringEntry(pdf, left, pdf.GetY(), m, rows)
+			pdf.SetXY(left, pdf.GetY()+sum(rows))
+		}
+	}
+
+	// The notes are guidance of the TGO template, printed in blue.
+	pdf.SetTextColor(0, 0, 255)
+	generateTableNotes(pdf, t.Notes)
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// drawMonitoringEntry draws the lines of m at x, y, rows high, leaving the
+// position unchanged.
+func drawMonitoringEntry(pdf *gofpdf.Fpdf, x, y float64, m Monitoring, rows []float64) {
+	h := sum(rows)
+	cell := func(x, y, w, h float64, text string) {
+		pdf.Rect(x, y, w, h, "D")
+		lines := splitLines(pdf, text, w)
+		textY := y + (h-monitoringLineHeight*float64(len(lines)))/2
+		drawLines(pdf, x, textY, w, monitoringLineHeight, lines)
+	}
+	item := func(items []string, i int) string {
+		if i < len(items) {
+			return items[i]
+		}
+		return ""
+	}
+
+	// The left edge of each column.
+	cols := make([]float64, len(monitoringWidth))
+	cols[0] = x
+	for i := 1; i < len(cols); i++ {
+		cols[i] = cols[i-1] + monitoringWidth[i-1]
+	}
+
+	cell(cols[0], y, monitoringWidth[0], h, strings.TrimSpace(m.Source))
+	ry := y
+	for i, rh := range rows {
+		for _, col := range []struct {
+			i     int
+			items []string
+		}{{1, m.Units}, {2, m.Points}, {6, m.Evidence}} {
+			cell(cols[col.i], ry, monitoringWidth[col.i], rh, item(col.items, i))
+		}
+		ry += rh
+	}
+	for i, origin := range monitoringOrigins {
+		mark := ""
+		if m.Origin == origin {
+			mark = "X"
+		}
+		pdf.SetXY(cols[3+i], y)
+		pdf.CellFormat(monitoringWidth[3+i], h, mark, "1", 0, "C", false, 0, "")
+	}
+	cell(cols[7], y, monitoringWidth[7], h, m.EFSource)
+}
+
+// generateMonitoringHeader draws the two-row header of a table of section 4
+// at the left margin, with the markers of the notes on its cells. The
+// header is row 0 of notes and its columns those of monitoringWidth.
+func generateMonitoringHeader(pdf *gofpdf.Fpdf, notes *TableNotes) {
+	mark := func(x, y, w, lineHeight float64, text string, col int) {
+		if marker := notes.marker(0, col); marker != "" {
+			drawNoteMarker(pdf, x, y, w, lineHeight, text, marker)
+		}
+	}
+
+	left, _, _, _ := pdf.GetMargins()
+	pdf.SetX(left)
+	pdf.SetFillColor(190, 190, 190)
+	pdf.SetFont("THSarabunNew", "B", 16)
+	pdf.CellFormat(35, 7.5, "", "TL", 0, "L", true, 0, "")
+	pdf.CellFormat(110, 7.5, "ข้อมูลกิจกรรม", "1", 0, "C", true, 0, "")
+	pdf.SetFont("THSarabunNew", "B", 12)
+	pdf.MultiCell(10, 7.5, "ค่า EF", "1", "L", true)
+
+	pdf.SetFont("THSarabunNew", "", 12)
+	pdf.SetX(left)
+	x, y := pdf.GetXY()
+	pdf.MultiCell(35, 22.5, "แหล่งปล่อยก๊าซเรือนกระจก ", "BL", "C", true)
+	pdf.SetXY(x+35, y)
+	x, y = pdf.GetXY()
+	pdf.MultiCell(15, 7.5, "ลักษณะข้อมูลกิจกรรมที่ตรวจวัด ", "1", "L", true)
+	pdf.SetXY(x+15, y)
+	x, y = pdf.GetXY()
+	pdf.MultiCell(15, 11.25, "จุดที่\nตรวจวัด", "1", "L", true)
+	pdf.SetXY(x+15, y)
+	x, y = pdf.GetXY()
+	pdf.MultiCell(54, 7.5, "ที่มาของข้อมูลกิจกรรม ", "1", "C", true)
+	pdf.SetXY(x, y+7.5)
+	x, y = pdf.GetXY()
+	pdf.MultiCell(18, 7.5, "เป็นค่าที่ได้จากการตรวจวัด", "1", "L", true)
+	mark(x, y, 18, 7.5, "เป็นค่าที่ได้จากการตรวจวัด", 3)
+	pdf.SetXY(x+18, y)
+	x, y = pdf.GetXY()
+	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากหลักฐานการชำระเงิน", "1", "L", true)
+	pdf.SetXY(x+18, y)
+	x, y = pdf.GetXY()
+	pdf.MultiCell(18, 5, "เป็นค่าที่ได้จากการประเมินค่า", "1", "L", true)
+	mark(x, y, 18, 5, "เป็นค่าที่ได้จากการประเมินค่า", 5)
+	pdf.SetXY(x+18, y-7.5)
+	x, y = pdf.GetXY()
+	pdf.MultiCell(26, 11.25, "หลักฐาน/\nเอกสารอ้างอิง", "1", "L", true)
+	pdf.SetXY(x+26, y)
+	x, y = pdf.GetXY()
+	pdf.MultiCell(10, 7.5, "ที่มา\nของค่า EF", "1", "L", true)
+	mark(x, y, 10, 7.5, "ที่มา\nของค่า EF", 7)
+}
+
+// monitoringNotes returns the notes under the table of section 4 of scope,
+// or of the sources reported separately in 4.4 when scope is 0, each on the
+// header cell of its column. Scope 2 is bought energy, whose activity data
+// is never an amount of emissions, so its table has no note on the
+// "ที่มาของค่า EF" column.
+func monitoringNotes(scope int) *TableNotes {
+	notes := &TableNotes{Title: "หมายเหตุ"}
+	notes.AddCell(0, 3, "ข้อมูลกิจกรรมที่ได้จากการตรวจวัด ให้ระบุรายละเอียดการสอบเทียบของอุปกรณ์ตรวจวัดไว้ในตารางที่ 7.3")
+	notes.AddCell(0, 5, "ข้อมูลกิจกรรมที่ได้จากการประมาณค่า ให้อธิบายแนวทางในการประมาณในตารางหรืออธิบายเพิ่มเติมในภาคผนวก")
+	if scope != 2 {
+		notes.AddCell(0, 7, "ในกรณีที่ข้อมูลกิจกรรมเป็นข้อมูลปริมาณการปล่อยก๊าซเรือนกระจกอยู่แล้ว เช่น ปริมาณการรั่วซึมของสารทำความเย็น ให้กรอกคำว่า “ไม่ต้องใช้ค่า EF” ลงในคอลัมน์ “ที่มาของค่า EF”")
+	}
+	return notes
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// TableNotes holds the notes printed under a table, such as
+// "หมายเหตุ : 1. มีนัยสำคัญ ...". Notes attached to a cell get a numbered
+// marker that is drawn in superscript after the cell text; notes on the
+// whole table are listed without a marker in the table.
+type TableNotes struct {
+	// Title is printed above the notes; empty means "หมายเหตุ".
+	Title string
+
+	notes []tableNote
+}
+
+type tableNote struct {
+	row, col int // -1 for a note on the whole table
+	text     string
+}
+
+const (
+	noteFontSize   = 10.0
+	noteLineHeight = 7.5
+)
+
+// Add attaches a note to the whole table.
+func (n *TableNotes) Add(text string) {
+	n.notes = append(n.notes, tableNote{row: -1, col: -1, text: text})
+}
+
+// AddCell attaches a note to the cell at row, col of the table data.
+func (n *TableNotes) AddCell(row, col int, text string) {
+	n.notes = append(n.notes, tableNote{row: row, col: col, text: text})
+}
+
+// numbered returns the notes in the order they are numbered: cell notes in
+// the order their cells appear in the table, then the table notes.
+func (n *TableNotes) numbered() []tableNote {
+	if n == nil {
+		return nil
+	}
+	notes := append([]tableNote(nil), n.notes...)
+	sort.SliceStable(notes, func(i, j int) bool {
+		a, b := notes[i], notes[j]
+		if (a.row < 0) != (b.row < 0) {
+			return b.row < 0
+		}
+		if a.row != b.row {
+			return a.row < b.row
+		}
+		return a.col < b.col
+	})
+	return notes
+}
+
+// marker returns the superscript marker of the cell at row, col, e.g. "(1)"
+// or "(1,2)", or "" when the cell has no notes.
+func (n *TableNotes) marker(row, col int) string {
+	var numbers []string
+	for i, note := range n.numbered() {
+		if note.row == row && note.col == col {
+			numbers = append(numbers, strconv.Itoa(i+1))
+		}
+	}
+	if len(numbers) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(numbers, ",") + ")"
+}
+
+func (n *TableNotes) list() List {
+	list := List{Style: ListNumberBracket}
+	for _, note := range n.numbered() {
+		list.Items = append(list.Items, ListItem{Text: note.text})
+	}
+	return list
+}
+
+func (n *TableNotes) title() string {
+	if n.Title == "" {
+		return "หมายเหตุ"
+	}
+	return n.Title
+}
+
+// height returns the space the notes take at width w. It leaves the note
+// font selected.
+func (n *TableNotes) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	if n == nil || len(n.notes) == 0 {
+		return 0
+	}
+	pdf.SetFont("THSarabunNew", "", noteFontSize)
+	return noteLineHeight * (1 + countListLines(pdf, n.list(), w))
+}
+
+// generateTableNotes draws notes below the current position.
+func generateTableNotes(pdf *gofpdf.Fpdf, notes *TableNotes) {
+	if notes == nil || len(notes.notes) == 0 {
+		return
+	}
+	top, bottom := contentBounds(pdf)
+	left, _, _, _ := pdf.GetMargins()
+	w := contentWidth(pdf)
+	height := notes.height(pdf, w)
+
+	y := pdf.GetY()
+	if y+height > bottom {
+		pdf.AddPage()
+		y = top
+	}
+
+	pdf.SetFont("THSarabunNew", "", noteFontSize)
+	pdf.SetXY(left, y)
+	pdf.CellFormat(w, noteLineHeight, notes.title(), "", 1, "L", false, 0, "")
+	y += noteLineHeight
+
+	for _, line := range layoutList(pdf, notes.list(), w, 0) {
+		drawListLine(pdf, left, y, w, noteLineHeight, line)
+		y += noteLineHeight
+	}
+	pdf.SetY(y)
+}
+
+// drawNoteMarker draws marker in superscript after the last line of text
+// in a w wide cell whose first line is at x, y and whose lines are
+// lineHeight apart.
+func drawNoteMarker(pdf *gofpdf.Fpdf, x, y, w, lineHeight float64, text, marker string) {
+	fontSize, unitSize := pdf.GetFontSize()
+	cellMargin := pdf.GetCellMargin()
+
+	end := cellMargin
+	if list, ok := parseList(text); ok {
+		if lines := layoutList(pdf, list, w, 0); len(lines) > 0 {
+			last := lines[len(lines)-1]
+			end += last.textX + pdf.GetStringWidth(last.text)
+			y += float64(len(lines)-1) * lineHeight
+		}
+	} else if lines := splitLines(pdf, text, w); len(lines) > 0 {
+		end += pdf.GetStringWidth(lines[len(lines)-1])
+		y += float64(len(lines)-1) * lineHeight
+	}
+
+	// Same baseline as CellFormat, raised by 40% of the text size.
+	baseline := y + 0.5*lineHeight + 0.3*unitSize
+	pdf.SetFontSize(fontSize * 0.6)
+	pdf.Text(x+end, baseline-0.4*unitSize, marker)
+	pdf.SetFontSize(fontSize)
+}
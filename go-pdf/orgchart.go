@@ -0,0 +1,242 @@
+package main
+
+import (
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// OrgUnit is a unit or position of the organisation with the person who
+// holds it. Role and Duty are its part in data quality management, listed
+// in the table of section 7.1.
+type OrgUnit struct {
+	Name     string
+	Person   string
+	Role     string
+	Duty     string
+	Children []*OrgUnit
+}
+
+// OrgChart draws the tree under Root as boxes joined by connectors, scaled
+// down to fit the content width when it is wider.
+type OrgChart struct {
+	Root   *OrgUnit
+	Figure *Caption
+}
+
+const (
+	orgBoxWidth    = 45.0
+	orgBoxPadding  = 2.0
+	orgFontSize    = 12.0
+	orgLineHeight  = 6.0
+	orgColumnGap   = 6.0
+	orgLevelGap    = 12.0
+	orgBorderWidth = 0.3
+)
+
+// orgNode is a laid out unit: the centre of its box and its level.
+type orgNode struct {
+	unit  *OrgUnit
+	x     float64
+	level int
+	name  []string
+	// parent is the index of the parent node, or -1 for the root.
+	parent int
+}
+
+// reportOrganisation is the organisation of the report, shown in section
+// 3.1.1 and listed by role in section 7.1.
+var reportOrganisation = &OrgUnit{
+	Name: "บริษัท เบทาโกร จำกัด (มหาชน) โรงงานผลิตอาหารสัตว์ จ.ลพบุรี",
+	Children: []*OrgUnit{
+		{Name: "ผู้จัดการโรงงาน BTG LR1", Person: "คุณ ไกรศกด กลบทอง", Role: "ผู้จัดการ\nโรงงาน", Duty: "กำหนดนโยบายในการ\nบริหารงานขององค์กร"},
+		{Name: "ผู้จัดการฝ่ายผลิตโรงงาน", Person: "คุณ ภาคภูมิ สีแก้วสิ่ว", Role: "ผู้จัดการ\nโรงงาน", Duty: "กำหนดนโยบายในการ\nบริหารงานขององค์กร"},
+		{Name: "ผู้จัดการโรงงาน BTG LR3", Person: "คุณ บรรจบ ศฤงคารินทร์", Role: "ผู้จัดการ\nโรงงาน", Duty: "กำหนดนโยบายในการ\nบริหารงานขององค์กร"},
+	},
+}
+
+// units returns the units of the tree in depth-first order.
+func (u *OrgUnit) units() []*OrgUnit {
+	units := []*OrgUnit{u}
+	for _, child := range u.Children {
+		units = append(units, child.units()...)
+	}
+	return units
+}
+
+// layout places every unit of the chart, leaves side by side and parents
+// centred over their children, and returns the nodes with the width of the
+// chart and the box height of each level, all before scaling.
+func (c OrgChart) layout(pdf *gofpdf.Fpdf) (nodes []orgNode, width float64, levels []float64) {
+	pdf.SetFont("THSarabunNew", "B", orgFontSize)
+	next := 0.0
+	var place func(unit *OrgUnit, level, parent int) float64
+	place = func(unit *OrgUnit, level, parent int) float64 {
+		i := len(nodes)
+		nodes = append(nodes, orgNode{unit: unit, level: level, parent: parent})
+		nodes[i].name = splitLines(pdf, unit.Name, orgBoxWidth)
+
+		if len(unit.Children) == 0 {
+			nodes[i].x = next + orgBoxWidth/2
+			next += orgBoxWidth + orgColumnGap
+		} else {
+			first := place(unit.Children[0], level+1, i)
+			last := first
+			for _, child := range unit.Children[1:] {
+				last = place(child, level+1, i)
+			}
+			nodes[i].x = (first + last) / 2
+		}
+
+		lines := len(nodes[i].name)
+		if unit.Person != "" {
+			lines++
+		}
+		for len(levels) <= level {
+			levels = append(levels, 0)
+		}
+		levels[level] = math.Max(levels[level], orgLineHeight*float64(lines)+2*orgBoxPadding)
+		return nodes[i].x
+	}
+	if c.Root != nil {
+		place(c.Root, 0, -1)
+	}
+	return nodes, math.Max(0, next-orgColumnGap), levels
+}
+
+// scale returns the factor the chart is drawn at to fit width w.
+func (c OrgChart) scale(width, w float64) float64 {
+	if width <= w || width == 0 {
+		return 1
+	}
+	return w / width
+}
+
+func (c OrgChart) height(pdf *gofpdf.Fpdf, w float64) float64 {
+	_, width, levels := c.layout(pdf)
+	h := (sum(levels) + orgLevelGap*float64(max(0, len(levels)-1))) * c.scale(width, w)
+	if c.Figure != nil {
+		h += captionGap + c.Figure.height(pdf, w)
+	}
+	return h
+}
+
+func (c OrgChart) leadHeight(pdf *gofpdf.Fpdf, w float64) float64 {
+	return c.height(pdf, w)
+}
+
+func (c OrgChart) render(pdf *gofpdf.Fpdf, w float64) {
+	left, _, _, _ := pdf.GetMargins()
+	y := pdf.GetY()
+	if c.Figure != nil {
+		c.Figure.place(pdf)
+	}
+
+	nodes, width, levels := c.layout(pdf)
+	scale := c.scale(width, w)
+	x := left + math.Max(0, w-width)/2
+
+	// Tops of the levels, before scaling.
+	tops := make([]float64, len(levels))
+	for i := 1; i < len(levels); i++ {
+		tops[i] = tops[i-1] + levels[i-1] + orgLevelGap
+	}
+
+	lineWidth := pdf.GetLineWidth()
+	pdf.SetLineWidth(orgBorderWidth)
+	pdf.TransformBegin()
+	pdf.TransformScale(100*scale, 100*scale, x, y)
+
+	for _, node := range nodes {
+		boxX := x + node.x - orgBoxWidth/2
+		boxY := y + tops[node.level]
+		pdf.Rect(boxX, boxY, orgBoxWidth, levels[node.level], "D")
+
+		lineY := boxY + orgBoxPadding
+		pdf.SetFont("THSarabunNew", "B", orgFontSize)
+		for _, line := range node.name {
+			pdf.SetXY(boxX, lineY)
+			pdf.CellFormat(orgBoxWidth, orgLineHeight, line, "", 0, "C", false, 0, "")
+			lineY += orgLineHeight
+		}
+		if node.unit.Person != "" {
+			pdf.SetFont("THSarabunNew", "", orgFontSize)
+			pdf.SetXY(boxX, lineY)
+			pdf.CellFormat(orgBoxWidth, orgLineHeight, node.unit.Person, "", 0, "C", false, 0, "")
+		}
+
+		if node.parent >= 0 {
+			// Elbow connector from the bottom of the parent box to the top
+			// of this one, through the middle of the gap between levels.
+			parent := nodes[node.parent]
+			parentBottom := y + tops[parent.level] + levels[parent.level]
+			middle := parentBottom + orgLevelGap/2
+			pdf.Line(x+parent.x, parentBottom, x+parent.x, middle)
+			pdf.Line(x+parent.x, middle, x+node.x, middle)
+			pdf.Line(x+node.x, middle, x+node.x, boxY)
+		}
+	}
+
+	pdf.TransformEnd()
+	pdf.SetLineWidth(lineWidth)
+
+	y += (sum(levels) + orgLevelGap*float64(max(0, len(levels)-1))) * scale
+	if c.Figure != nil {
+		pdf.SetXY(left, y+captionGap)
+		c.Figure.render(pdf, w)
+		y = pdf.GetY()
+	}
+	pdf.SetXY(left, y)
+}
+
+// generateRolesTable draws the table of section 7.1: the units of org that
+// have a data quality role, with consecutive units of the same role sharing
+// the role and duty cells.
+func generateRolesTable(pdf *gofpdf.Fpdf, org *OrgUnit) {
+	left, _, _, _ := pdf.GetMargins()
+	rowHeight := 6.0
+
+	pdf.SetFont("THSarabunNew", "", 14)
+	x, y := pdf.GetXY()
+	pdf.MultiCell(25, 7.5, "บทบาท ", "1", "C", true)
+	pdf.SetXY(x+25, y)
+	pdf.MultiCell(50, 7.5, "ชื่อ-สกุล ", "1", "C", true)
+	pdf.SetXY(x+75, y)
+	pdf.MultiCell(50, 7.5, "ตำแหน่ง  ", "1", "C", true)
+	pdf.SetXY(x+125, y)
+	pdf.MultiCell(40, 7.5, "หน้าที่ ", "1", "C", true)
+
+	var units []*OrgUnit
+	for _, unit := range org.units() {
+		if unit.Role != "" {
+			units = append(units, unit)
+		}
+	}
+
+	y = pdf.GetY()
+	for start := 0; start < len(units); {
+		end := start + 1
+		for end < len(units) && units[end].Role == units[start].Role && units[end].Duty == units[start].Duty {
+			end++
+		}
+		groupHeight := rowHeight * float64(end-start)
+
+		for i, unit := range units[start:end] {
+			rowY := y + rowHeight*float64(i)
+			pdf.SetXY(left+25, rowY)
+			pdf.CellFormat(50, rowHeight, unit.Person, "1", 0, "C", false, 0, "")
+			pdf.CellFormat(50, rowHeight, unit.Name, "1", 0, "C", false, 0, "")
+		}
+		for _, cell := range []struct {
+			x, w float64
+			text string
+		}{{left, 25, units[start].Role}, {left + 125, 40, units[start].Duty}} {
+			pdf.Rect(cell.x, y, cell.w, groupHeight, "D")
+			lines := splitLines(pdf, cell.text, cell.w)
+			drawLines(pdf, cell.x, y+(groupHeight-rowHeight*float64(len(lines)))/2, cell.w, rowHeight, lines)
+		}
+		y += groupHeight
+		start = end
+	}
+	pdf.SetXY(left, y)
+}
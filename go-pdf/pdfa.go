@@ -0,0 +1,449 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PDF/A conformance level B (visual appearance) is the only level written:
+// gofpdf does not produce the tagged structure level A needs.
+const pdfaConformance = "B"
+
+// binaryComment marks the file as binary right after the header, as PDF/A
+// requires.
+const binaryComment = "%\xe2\xe3\xcf\xd3\n"
+
+// parsePDFAPart parses a -pdfa flag value such as "2b" into the part number.
+func parsePDFAPart(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "1b":
+		return 1, nil
+	case "2b":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("unsupported PDF/A level %q, want 1b or 2b", s)
+}
+
+// convertToPDFA turns a PDF written by gofpdf into PDF/A-1b or PDF/A-2b:
+// it adds the binary comment and appends an update with XMP metadata that
+// mirrors the document information dictionary, an sRGB output intent and a
+// file ID. Content that the part does not allow, such as transparency in
+// PDF/A-1, is left as it is for checkPDFA to report. The images of this
+// report have an alpha channel, which gofpdf writes as soft masks in
+// transparency groups, so only PDF/A-2b can be made from it.
+func convertToPDFA(data []byte, part int) ([]byte, error) {
+	data, err := addBinaryComment(data)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := dictRef(f.trailer, "Root")
+	if !ok {
+		return nil, errors.New("pdf: trailer has no /Root")
+	}
+	catalog, err := f.object(root)
+	if err != nil {
+		return nil, err
+	}
+	info := map[string]string{}
+	if ref, ok := dictRef(f.trailer, "Info"); ok {
+		dict, err := f.object(ref)
+		if err != nil {
+			return nil, err
+		}
+		info = infoEntries(dict)
+	}
+
+	metadata, profile := f.size(), f.size()+1
+	xmp := pdfaMetadata(info, part)
+	icc := srgbProfile()
+
+	catalog = strings.TrimSpace(catalog)
+	catalog = strings.TrimSuffix(strings.TrimPrefix(catalog, "<<"), ">>")
+	// gofpdf always writes an empty name tree of embedded files, which
+	// PDF/A-1 does not allow at all.
+	catalog = regexp.MustCompile(`/Names\s*<<\s*/EmbeddedFiles\s*<<\s*/Names\s*\[\s*\]\s*>>\s*>>`).ReplaceAllString(catalog, "")
+	catalog = regexp.MustCompile(`/Metadata\s+\d+\s+0\s+R`).ReplaceAllString(catalog, "")
+	catalog = fmt.Sprintf("<<%s/Metadata %d 0 R /OutputIntents [<< /Type /OutputIntent /S /GTS_PDFA1 "+
+		"/OutputConditionIdentifier (sRGB IEC61966-2.1) /Info (sRGB IEC61966-2.1) /DestOutputProfile %d 0 R >>] >>",
+		strings.TrimRight(catalog, " \n")+" ", metadata, profile)
+
+	return f.update([]pdfObject{
+		{num: root, body: []byte(catalog)},
+		{num: metadata, body: streamObject("/Type /Metadata /Subtype /XML", xmp)},
+		{num: profile, body: streamObject("/N 3", icc)},
+	}, ""), nil
+}
+
+// streamObject returns the body of an unfiltered stream object.
+func streamObject(dict string, data []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	b.Write(data)
+	b.WriteString("\nendstream")
+	return b.Bytes()
+}
+
+// hasBinaryComment reports whether the line after the header is a comment
+// of at least four bytes above 127.
+func hasBinaryComment(data []byte) bool {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 || i+5 >= len(data) || data[i+1] != '%' {
+		return false
+	}
+	for _, c := range data[i+2 : i+6] {
+		if c < 128 {
+			return false
+		}
+	}
+	return true
+}
+
+// addBinaryComment inserts binaryComment after the header of a file without
+// incremental updates and rewrites the cross-reference table for the moved
+// objects.
+func addBinaryComment(data []byte) ([]byte, error) {
+	if hasBinaryComment(data) {
+		return data, nil
+	}
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := dictInt(f.trailer, "Prev"); ok {
+		return nil, errors.New("pdf: cannot add the binary comment to an updated file")
+	}
+	header := bytes.IndexByte(data, '\n') + 1
+	shift := len(binaryComment)
+
+	var b bytes.Buffer
+	b.Write(data[:header])
+	b.WriteString(binaryComment)
+	b.Write(data[header:f.xref])
+
+	size := f.size()
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for num := 1; num < size; num++ {
+		if offset, ok := f.offsets[num]; ok {
+			fmt.Fprintf(&b, "%010d 00000 n \n", offset+shift)
+		} else {
+			b.WriteString("0000000000 00000 f \n")
+		}
+	}
+	b.WriteString(f.trailer)
+	fmt.Fprintf(&b, "startxref\n%d\n%%%%EOF\n", xref)
+	return b.Bytes(), nil
+}
+
+// infoKeys are the entries of the document information dictionary that
+// the XMP metadata has to repeat.
+var infoKeys = []string{"Title", "Author", "Subject", "Keywords", "Creator", "Producer", "CreationDate", "ModDate"}
+
+// infoEntries returns the text entries of an information dictionary.
+func infoEntries(dict string) map[string]string {
+	entries := map[string]string{}
+	for _, key := range infoKeys {
+		if value, ok := dictString(dict, key); ok {
+			entries[key] = value
+		}
+	}
+	return entries
+}
+
+// xmpDate converts a PDF date such as "D:20221231235959+07'00'" to an XMP
+// date such as "2022-12-31T23:59:59+07:00".
+func xmpDate(date string) string {
+	m := regexp.MustCompile(`^D:(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?(?:(Z)|([+-])(\d{2})'?(\d{2})?'?)?`).FindStringSubmatch(date)
+	if m == nil {
+		return date
+	}
+	part := func(i int, fallback string) string {
+		if m[i] == "" {
+			return fallback
+		}
+		return m[i]
+	}
+	s := fmt.Sprintf("%s-%s-%sT%s:%s:%s", m[1], part(2, "01"), part(3, "01"), part(4, "00"), part(5, "00"), part(6, "00"))
+	switch {
+	case m[7] != "":
+		s += "Z"
+	case m[8] != "":
+		s += m[8] + m[9] + ":" + part(10, "00")
+	}
+	return s
+}
+
+// xmlText escapes s for XML character data.
+func xmlText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// pdfaMetadata returns the XMP packet with the PDF/A identification and
+// the entries of info.
+func pdfaMetadata(info map[string]string, part int) []byte {
+	var b strings.Builder
+	b.WriteString("<?xpacket begin=\"\xef\xbb\xbf\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString("<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+
+	fmt.Fprintf(&b, "<rdf:Description rdf:about=\"\" xmlns:pdfaid=\"http://www.aiim.org/pdfa/ns/id/\">\n"+
+		"<pdfaid:part>%d</pdfaid:part>\n<pdfaid:conformance>%s</pdfaid:conformance>\n</rdf:Description>\n", part, pdfaConformance)
+
+	if info["Title"] != "" || info["Author"] != "" || info["Subject"] != "" {
+		b.WriteString("<rdf:Description rdf:about=\"\" xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n")
+		b.WriteString("<dc:format>application/pdf</dc:format>\n")
+		if v := info["Title"]; v != "" {
+			fmt.Fprintf(&b, "<dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:title>\n", xmlText(v))
+		}
+		if v := info["Author"]; v != "" {
+			fmt.Fprintf(&b, "<dc:creator><rdf:Seq><rdf:li>%s</rdf:li></rdf:Seq></dc:creator>\n", xmlText(v))
+		}
+		if v := info["Subject"]; v != "" {
+			fmt.Fprintf(&b, "<dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:description>\n", xmlText(v))
+		}
+		b.WriteString("</rdf:Description>\n")
+	}
+
+	b.WriteString("<rdf:Description rdf:about=\"\" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\">\n")
+	if v := info["Creator"]; v != "" {
+		fmt.Fprintf(&b, "<xmp:CreatorTool>%s</xmp:CreatorTool>\n", xmlText(v))
+	}
+	if v := info["CreationDate"]; v != "" {
+		fmt.Fprintf(&b, "<xmp:CreateDate>%s</xmp:CreateDate>\n", xmpDate(v))
+	}
+	if v := info["ModDate"]; v != "" {
+		fmt.Fprintf(&b, "<xmp:ModifyDate>%s</xmp:ModifyDate>\n<xmp:MetadataDate>%s</xmp:MetadataDate>\n", xmpDate(v), xmpDate(v))
+	}
+	b.WriteString("</rdf:Description>\n")
+
+	b.WriteString("<rdf:Description rdf:about=\"\" xmlns:pdf=\"http://ns.adobe.com/pdf/1.3/\">\n")
+	if v := info["Producer"]; v != "" {
+		fmt.Fprintf(&b, "<pdf:Producer>%s</pdf:Producer>\n", xmlText(v))
+	}
+	if v := info["Keywords"]; v != "" {
+		fmt.Fprintf(&b, "<pdf:Keywords>%s</pdf:Keywords>\n", xmlText(v))
+	}
+	b.WriteString("</rdf:Description>\n")
+
+	b.WriteString("</rdf:RDF>\n</x:xmpmeta>\n")
+	// Padding lets the packet be edited in place.
+	b.WriteString(strings.Repeat(strings.Repeat(" ", 99)+"\n", 20))
+	b.WriteString("<?xpacket end=\"w\"?>")
+	return []byte(b.String())
+}
+
+// srgbProfile returns an ICC version 2 display profile for sRGB
+// IEC61966-2.1, the output intent of the document: its primaries adapted
+// to D50 and a sampled tone curve.
+func srgbProfile() []byte {
+	xyz := func(x, y, z float64) []byte {
+		b := []byte("XYZ \x00\x00\x00\x00")
+		for _, v := range []float64{x, y, z} {
+			b = binary.BigEndian.AppendUint32(b, uint32(int32(math.Round(v*65536))))
+		}
+		return b
+	}
+	desc := func(s string) []byte {
+		b := []byte("desc\x00\x00\x00\x00")
+		b = binary.BigEndian.AppendUint32(b, uint32(len(s)+1))
+		b = append(b, s...)
+		b = append(b, 0)
+		// Empty Unicode and ScriptCode descriptions.
+		return append(b, make([]byte, 4+4+2+1+67)...)
+	}
+	curve := []byte("curv\x00\x00\x00\x00")
+	const samples = 1024
+	curve = binary.BigEndian.AppendUint32(curve, samples)
+	for i := 0; i < samples; i++ {
+		v := float64(i) / (samples - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		curve = binary.BigEndian.AppendUint16(curve, uint16(math.Round(v*65535)))
+	}
+
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", desc("sRGB IEC61966-2.1")},
+		{"cprt", append([]byte("text\x00\x00\x00\x00"), "No copyright, use freely\x00"...)},
+		{"wtpt", xyz(0.9505, 1.0, 1.0891)},
+		{"rXYZ", xyz(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyz(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyz(0.1431, 0.0606, 0.7141)},
+		{"rTRC", curve},
+		{"gTRC", curve},
+		{"bTRC", curve},
+	}
+
+	header := make([]byte, 128)
+	copy(header[8:], "\x02\x10\x00\x00mntrRGB XYZ ")
+	copy(header[24:], []byte{0x07, 0xe6, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0}) // 2022-01-01
+	copy(header[36:], "acsp")
+	copy(header[68:], xyz(0.9642, 1.0, 0.8249)[8:])
+
+	table := binary.BigEndian.AppendUint32(nil, uint32(len(tags)))
+	var data []byte
+	offset := len(header) + 4 + 12*len(tags)
+	shared := map[string]int{} // the three curves share their data
+	for _, tag := range tags {
+		at, ok := shared[string(tag.data)]
+		if !ok {
+			at = offset + len(data)
+			shared[string(tag.data)] = at
+			data = append(data, tag.data...)
+			for len(data)%4 != 0 {
+				data = append(data, 0)
+			}
+		}
+		table = append(table, tag.sig...)
+		table = binary.BigEndian.AppendUint32(table, uint32(at))
+		table = binary.BigEndian.AppendUint32(table, uint32(len(tag.data)))
+	}
+
+	profile := append(append(header, table...), data...)
+	binary.BigEndian.PutUint32(profile, uint32(len(profile)))
+	return profile
+}
+
+// checkPDFA returns the problems that keep data from conforming to
+// PDF/A-1b or PDF/A-2b as given by part, as far as they can be seen
+// without interpreting content streams.
+func checkPDFA(data []byte, part int) []string {
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !hasBinaryComment(data) {
+		report("no binary comment after the header")
+	}
+	f, err := parsePDF(data)
+	if err != nil {
+		return append(problems, err.Error())
+	}
+	if strings.Contains(f.trailer, "/Encrypt") {
+		report("the file is encrypted")
+	}
+	if dictID(f.trailer) == "" {
+		report("the trailer has no file ID")
+	}
+
+	var catalog, xmp string
+	if root, ok := dictRef(f.trailer, "Root"); !ok {
+		report("the trailer has no /Root")
+	} else if catalog, err = f.object(root); err != nil {
+		report("%v", err)
+	}
+	if metadata, ok := dictRef(catalog, "Metadata"); !ok {
+		report("the catalog has no XMP metadata")
+	} else if dict, err := f.object(metadata); err != nil {
+		report("%v", err)
+	} else if strings.Contains(dict, "/Filter") {
+		report("the XMP metadata stream is filtered")
+	} else if stream, err := f.stream(metadata); err != nil {
+		report("%v", err)
+	} else {
+		xmp = string(stream)
+		if !strings.Contains(xmp, "<pdfaid:part>"+strconv.Itoa(part)+"</pdfaid:part>") {
+			report("the XMP metadata does not declare PDF/A-%d", part)
+		}
+		if !strings.Contains(xmp, "<pdfaid:conformance>"+pdfaConformance+"</pdfaid:conformance>") {
+			report("the XMP metadata does not declare conformance %s", pdfaConformance)
+		}
+	}
+	if !strings.Contains(catalog, "/GTS_PDFA1") || !strings.Contains(catalog, "/DestOutputProfile") {
+		report("the catalog has no PDF/A output intent")
+	}
+
+	if ref, ok := dictRef(f.trailer, "Info"); ok && xmp != "" {
+		if dict, err := f.object(ref); err == nil {
+			for key, value := range infoEntries(dict) {
+				if strings.HasSuffix(key, "Date") {
+					value = xmpDate(value)
+				} else {
+					value = xmlText(value)
+				}
+				if !strings.Contains(xmp, ">"+value+"<") {
+					report("the XMP metadata does not match /%s of the document information", key)
+				}
+			}
+		}
+	}
+
+	// Problems found in objects are reported once with all the objects.
+	var found []string
+	objects := map[string][]string{}
+	flag := func(num int, problem string) {
+		if _, ok := objects[problem]; !ok {
+			found = append(found, problem)
+		}
+		objects[problem] = append(objects[problem], strconv.Itoa(num))
+	}
+
+	actions := regexp.MustCompile(`/S\s*/(JavaScript|Launch|Sound|Movie|ResetForm|ImportData)\b`)
+	blend := regexp.MustCompile(`/BM\s*/(\w+)`)
+	alpha := regexp.MustCompile(`/(CA|ca)\s+([0-9.]+)`)
+	for _, num := range f.objectNumbers() {
+		dict, err := f.object(num)
+		if err != nil {
+			report("%v", err)
+			continue
+		}
+		if m := actions.FindStringSubmatch(dict); m != nil {
+			flag(num, m[1]+" action")
+		}
+		if strings.Contains(dict, "/JS ") || strings.Contains(dict, "/JS(") {
+			flag(num, "JavaScript")
+		}
+		if strings.Contains(dict, "/LZWDecode") {
+			flag(num, "LZW compression")
+		}
+		if strings.Contains(dict, "/Type /FontDescriptor") && !regexp.MustCompile(`/FontFile[23]?\s`).MatchString(dict) {
+			name, _, _ := strings.Cut(strings.TrimPrefix(dict[strings.Index(dict, "/FontName")+len("/FontName"):], " "), " ")
+			report("font %s is not embedded", name)
+		}
+		if part > 1 {
+			continue
+		}
+		// PDF/A-1 has no transparency and no embedded files.
+		if regexp.MustCompile(`/SMask\s+\d+\s+0\s+R`).MatchString(dict) {
+			flag(num, "soft mask (not allowed in PDF/A-1)")
+		}
+		if strings.Contains(dict, "/S /Transparency") {
+			flag(num, "transparency group (not allowed in PDF/A-1)")
+		}
+		if m := blend.FindStringSubmatch(dict); m != nil && m[1] != "Normal" && m[1] != "Compatible" {
+			flag(num, m[1]+" blend mode (not allowed in PDF/A-1)")
+		}
+		for _, m := range alpha.FindAllStringSubmatch(dict, -1) {
+			if v, _ := strconv.ParseFloat(m[2], 64); v != 1 {
+				flag(num, "constant alpha (not allowed in PDF/A-1)")
+			}
+		}
+		if strings.Contains(dict, "/EmbeddedFiles") {
+			flag(num, "embedded files (not allowed in PDF/A-1)")
+		}
+	}
+	for _, problem := range found {
+		report("objects %s: %s", strings.Join(objects[problem], ", "), problem)
+	}
+	return problems
+}
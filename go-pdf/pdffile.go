@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// pdfFile is a PDF as written by gofpdf, read far enough to look up
+// objects and to append incremental updates. Only cross-reference tables
+// are supported, not cross-reference streams.
+type pdfFile struct {
+	data    []byte
+	offsets map[int]int // object number to offset of "n 0 obj"
+	trailer string      // the last trailer dictionary
+	xref    int         // offset of the last cross-reference table
+}
+
+// pdfObject is an object to be written in an incremental update.
+type pdfObject struct {
+	num  int
+	body []byte // everything between "n 0 obj" and "endobj"
+}
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF`)
+	subsectionHeader = regexp.MustCompile(`^(\d+) (\d+)$`)
+)
+
+// parsePDF reads the cross-reference tables and the last trailer of data.
+func parsePDF(data []byte) (*pdfFile, error) {
+	// The last startxref wins; anything after its %%EOF is ignored.
+	all := startxrefPattern.FindAllSubmatch(data, -1)
+	if all == nil {
+		return nil, errors.New("pdf: startxref not found")
+	}
+	f := &pdfFile{data: data, offsets: map[int]int{}}
+	f.xref, _ = strconv.Atoi(string(all[len(all)-1][1]))
+
+	for offset, first := f.xref, true; ; first = false {
+		trailer, err := f.readXref(offset)
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			f.trailer = trailer
+		}
+		prev, ok := dictInt(trailer, "Prev")
+		if !ok {
+			break
+		}
+		offset = prev
+	}
+	return f, nil
+}
+
+// readXref reads the cross-reference table at offset, keeping offsets
+// already known from later tables, and returns the trailer after it.
+func (f *pdfFile) readXref(offset int) (string, error) {
+	if offset < 0 || offset >= len(f.data) || !bytes.HasPrefix(f.data[offset:], []byte("xref")) {
+		return "", fmt.Errorf("pdf: no xref table at offset %d", offset)
+	}
+	end := bytes.Index(f.data[offset:], []byte("trailer"))
+	if end < 0 {
+		return "", errors.New("pdf: trailer not found")
+	}
+	lines := strings.Fields(strings.ReplaceAll(string(f.data[offset+len("xref"):offset+end]), "\r", "\n"))
+	for i := 0; i+1 < len(lines); {
+		m := subsectionHeader.FindStringSubmatch(lines[i] + " " + lines[i+1])
+		if m == nil {
+			return "", fmt.Errorf("pdf: bad xref subsection at offset %d", offset)
+		}
+		start, _ := strconv.Atoi(m[1])
+		count, _ := strconv.Atoi(m[2])
+		i += 2
+		for j := 0; j < count && i+2 < len(lines); j++ {
+			entryOffset, _ := strconv.Atoi(lines[i])
+			if _, known := f.offsets[start+j]; !known && lines[i+2] == "n" {
+				f.offsets[start+j] = entryOffset
+			}
+			i += 3
+		}
+	}
+
+	trailer := f.data[offset+end:]
+	if i := bytes.Index(trailer, []byte("startxref")); i >= 0 {
+		trailer = trailer[:i]
+	}
+	return string(trailer), nil
+}
+
+// object returns the dictionary of object num, without the stream data of
+// stream objects.
+func (f *pdfFile) object(num int) (string, error) {
+	body, err := f.objectBody(num)
+	if err != nil {
+		return "", err
+	}
+	if i := bytes.Index(body, []byte("stream")); i >= 0 {
+		body = body[:i]
+	}
+	return string(body), nil
+}
+
+// stream returns the raw data of stream object num.
+func (f *pdfFile) stream(num int) ([]byte, error) {
+	body, err := f.objectBody(num)
+	if err != nil {
+		return nil, err
+	}
+	start := bytes.Index(body, []byte("stream"))
+	end := bytes.LastIndex(body, []byte("endstream"))
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("pdf: object %d is not a stream", num)
+	}
+	data := body[start+len("stream") : end]
+	data = bytes.TrimPrefix(data, []byte("\r"))
+	data = bytes.TrimPrefix(data, []byte("\n"))
+	return bytes.TrimSuffix(bytes.TrimSuffix(data, []byte("\n")), []byte("\r")), nil
+}
+
+func (f *pdfFile) objectBody(num int) ([]byte, error) {
+	offset, ok := f.offsets[num]
+	if !ok {
+		return nil, fmt.Errorf("pdf: object %d not found", num)
+	}
+	header := []byte(strconv.Itoa(num) + " 0 obj")
+	if !bytes.HasPrefix(f.data[offset:], header) {
+		return nil, fmt.Errorf("pdf: object %d not at offset %d", num, offset)
+	}
+	body := f.data[offset+len(header):]
+	end := bytes.Index(body, []byte("endobj"))
+	if end < 0 {
+		return nil, fmt.Errorf("pdf: object %d has no endobj", num)
+	}
+	return body[:end], nil
+}
+
+// size returns the next free object number.
+func (f *pdfFile) size() int {
+	size, _ := dictInt(f.trailer, "Size")
+	for num := range f.offsets {
+		size = max(size, num+1)
+	}
+	return size
+}
+
+// objectNumbers returns the numbers of all objects in use, in order.
+func (f *pdfFile) objectNumbers() []int {
+	nums := make([]int, 0, len(f.offsets))
+	for num := range f.offsets {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// update appends objects to the file as an incremental update, with a
+// trailer that keeps /Root, /Info and the first file ID, plus extra
+// entries. The original bytes are left untouched.
+func (f *pdfFile) update(objects []pdfObject, extra string) []byte {
+	var b bytes.Buffer
+	b.Write(f.data)
+	if !bytes.HasSuffix(f.data, []byte("\n")) {
+		b.WriteString("\n")
+	}
+
+	offsets := map[int]int{}
+	for _, obj := range objects {
+		offsets[obj.num] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n", obj.num)
+		b.Write(obj.body)
+		if !bytes.HasSuffix(obj.body, []byte("\n")) {
+			b.WriteString("\n")
+		}
+		b.WriteString("endobj\n")
+	}
+
+	nums := make([]int, 0, len(offsets))
+	size := f.size()
+	for num := range offsets {
+		nums = append(nums, num)
+		size = max(size, num+1)
+	}
+	sort.Ints(nums)
+
+	xref := b.Len()
+	b.WriteString("xref\n")
+	for i := 0; i < len(nums); {
+		j := i + 1
+		for j < len(nums) && nums[j] == nums[j-1]+1 {
+			j++
+		}
+		fmt.Fprintf(&b, "%d %d\n", nums[i], j-i)
+		for _, num := range nums[i:j] {
+			fmt.Fprintf(&b, "%010d 00000 n \n", offsets[num])
+		}
+		i = j
+	}
+
+	id := md5.Sum(b.Bytes())
+	first := dictID(f.trailer)
+	if first == "" {
+		first = fmt.Sprintf("%X", id)
+	}
+
+	b.WriteString("trailer\n<<\n")
+	fmt.Fprintf(&b, "/Size %d\n", size)
+	for _, key := range []string{"Root", "Info"} {
+		if ref, ok := dictRef(f.trailer, key); ok {
+			fmt.Fprintf(&b, "/%s %d 0 R\n", key, ref)
+		}
+	}
+	fmt.Fprintf(&b, "/Prev %d\n", f.xref)
+	fmt.Fprintf(&b, "/ID [<%s> <%X>]\n", first, id)
+	b.WriteString(extra)
+	b.WriteString(">>\nstartxref\n")
+	fmt.Fprintf(&b, "%d\n%%%%EOF\n", xref)
+	return b.Bytes()
+}
+
+// dictRef returns the object number of the indirect reference under key.
+func dictRef(dict, key string) (int, bool) {
+	m := regexp.MustCompile(`/` + key + `\s+(\d+)\s+0\s+R`).FindStringSubmatch(dict)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	return n, err == nil
+}
+
+// dictInt returns the integer under key.
+func dictInt(dict, key string) (int, bool) {
+	m := regexp.MustCompile(`/` + key + `\s+(\d+)\b(\s+0\s+R)?`).FindStringSubmatch(dict)
+	if m == nil || m[2] != "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	return n, err == nil
+}
+
+// dictID returns the first file ID of a trailer as hex, or "".
+func dictID(trailer string) string {
+	m := regexp.MustCompile(`/ID\s*\[\s*<([0-9A-Fa-f]*)>`).FindStringSubmatch(trailer)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// dictString returns the text string under key, decoding escapes and
+// UTF-16 text.
+func dictString(dict, key string) (string, bool) {
+	i := strings.Index(dict, "/"+key+" (")
+	if i < 0 {
+		i = strings.Index(dict, "/"+key+"(")
+		if i < 0 {
+			return "", false
+		}
+	}
+	s := dict[strings.Index(dict[i:], "(")+i+1:]
+
+	var raw []byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				raw = append(raw, '\n')
+			case 'r':
+				raw = append(raw, '\r')
+			case 't':
+				raw = append(raw, '\t')
+			case 'b':
+				raw = append(raw, '\b')
+			case 'f':
+				raw = append(raw, '\f')
+			case '\n':
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				j := i
+				for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+					j++
+				}
+				n, _ := strconv.ParseUint(s[i:j], 8, 8)
+				raw = append(raw, byte(n))
+				i = j - 1
+			default:
+				raw = append(raw, s[i])
+			}
+		case c == '(':
+			depth++
+			raw = append(raw, c)
+		case c == ')':
+			if depth == 0 {
+				return decodeTextString(raw), true
+			}
+			depth--
+			raw = append(raw, c)
+		default:
+			raw = append(raw, c)
+		}
+	}
+	return "", false
+}
+
+// decodeTextString decodes a PDF text string: UTF-16BE after a byte order
+// mark, otherwise single bytes.
+func decodeTextString(raw []byte) string {
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	runes := make([]rune, len(raw))
+	for i, c := range raw {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// encodeTextString returns s as a PDF text string: a literal string when it
+// is printable ASCII, otherwise UTF-16BE with a byte order mark in hex.
+func encodeTextString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s) + ")"
+	}
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// pdfDate formats t as a PDF date with its time zone, e.g.
+// "D:20230628090000+07'00'".
+func pdfDate(t time.Time) string {
+	s := t.Format("D:20060102150405")
+	_, offset := t.Zone()
+	if offset == 0 {
+		return s + "Z"
+	}
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	return fmt.Sprintf("%s%s%02d'%02d'", s, sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Protection encrypts the report with gofpdf's standard security handler.
+// UserPassword is asked for to open the document, OwnerPassword gives full
+// access regardless of Allow. Allow lists the actions readers may take
+// with the user password, as accepted by parsePermissions; empty allows
+// them all.
+type Protection struct {
+	UserPassword  string
+	OwnerPassword string
+	Allow         string
+}
+
+// permissions are the actions that can be allowed by name.
+var permissions = map[string]byte{
+	"print":       gofpdf.CnProtectPrint,
+	"modify":      gofpdf.CnProtectModify,
+	"copy":        gofpdf.CnProtectCopy,
+	"annot-forms": gofpdf.CnProtectAnnotForms,
+}
+
+// enabled reports whether any protection was asked for.
+func (p Protection) enabled() bool {
+	return p.UserPassword != "" || p.OwnerPassword != "" || p.Allow != ""
+}
+
+// parsePermissions parses a comma separated list of the names in
+// permissions, or "none", into gofpdf's action flags.
+func parsePermissions(s string) (byte, error) {
+	var flags byte
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "none" || name == "" {
+			continue
+		}
+		flag, ok := permissions[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown permission %q, want print, modify, copy, annot-forms or none", name)
+		}
+		flags |= flag
+	}
+	return flags, nil
+}
+
+// apply sets the protection of pdf. Without an owner password gofpdf makes
+// up a random one, so nobody gets full access.
+func (p Protection) apply(pdf *gofpdf.Fpdf) error {
+	if !p.enabled() {
+		return nil
+	}
+	flags := byte(gofpdf.CnProtectPrint | gofpdf.CnProtectModify | gofpdf.CnProtectCopy | gofpdf.CnProtectAnnotForms)
+	if p.Allow != "" {
+		var err error
+		if flags, err = parsePermissions(p.Allow); err != nil {
+			return err
+		}
+	}
+	pdf.SetProtection(flags, p.UserPassword, p.OwnerPassword)
+	return nil
+}
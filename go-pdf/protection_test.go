@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+func TestParsePermissions(t *testing.T) {
+	tests := []struct {
+		s     string
+		flags byte
+	}{
+		{"", 0},
+		{"none", 0},
+		{"print", gofpdf.CnProtectPrint},
+		{"Print, copy", gofpdf.CnProtectPrint | gofpdf.CnProtectCopy},
+		{"modify,annot-forms", gofpdf.CnProtectModify | gofpdf.CnProtectAnnotForms},
+	}
+	for _, tt := range tests {
+		flags, err := parsePermissions(tt.s)
+		if err != nil || flags != tt.flags {
+			t.Errorf("parsePermissions(%q) = %d, %v; want %d", tt.s, flags, err, tt.flags)
+		}
+	}
+	if _, err := parsePermissions("print,bogus"); err == nil {
+		t.Error("unknown permission accepted")
+	}
+}
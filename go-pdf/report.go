@@ -0,0 +1,558 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// ReportInfo identifies the report: the organisation, who prepared and
+// verifies it, the period it covers and the form it follows. It fills the
+// cover, the page header and footer and the document properties.
+type ReportInfo struct {
+	Title        string
+	Organisation string
+	Address      string
+	Preparer     string
+	Verifier     string
+	Period       string
+	Date         time.Time
+	FormCode     string
+	FormVersion  string
+	FormDate     string
+}
+
+// reportInfo is the information shown on the cover.
+var reportInfo = ReportInfo{
+	Title:        "รายงานการปล่อยและดูดกลับก๊าซเรือนกระจก",
+	Organisation: "บริษัท เบทาโกร จำกัด (มหาชน) โรงงานผลิตอาหารสัตว์ จ.ลพบุรี 1,2 และ 3",
+	Address:      "เลขที่ 3 หมู่ 13 ถ.สระบุรี-หล่มสัก ต.ช่องสาริกา อ.พัฒนานิคม จ.ลพบุรี",
+	Preparer:     "สภาอุตสาหกรรมแห่งประเทศไทย",
+	Verifier:     "บริษัท อีซีอีอี จำกัด",
+	Period:       "มกราคม ถึง ธันวาคม 2565",
+	Date:         time.Date(2023, time.June, 28, 0, 0, 0, 0, time.Local),
+	FormCode:     "TCFO_R_02",
+	FormVersion:  "03.00",
+	FormDate:     "24/4/2019",
+}
+
+// Form returns the form the report follows, e.g. "TCFO_R_02 Version 03.00".
+func (r ReportInfo) Form() string {
+	return r.FormCode + " Version " + r.FormVersion
+}
+
+// setMetadata fills the document information of pdf from the report, so
+// that document management systems can index it. The report date is both
+// the creation and the modification date, which keeps the output of the
+// same data the same.
+func (r ReportInfo) setMetadata(pdf *gofpdf.Fpdf) {
+	pdf.SetTitle(r.Title, true)
+	pdf.SetAuthor(r.Organisation, true)
+	pdf.SetSubject(r.Title+" "+r.Organisation+" ระยะเวลา "+r.Period+" ทวนสอบโดย "+r.Verifier, true)
+	pdf.SetKeywords(strings.Join([]string{r.FormCode, r.Form(), r.Organisation, r.Verifier, r.Period}, "; "), true)
+	pdf.SetCreator(r.Form()+" "+r.FormDate, true)
+	pdf.SetCreationDate(r.Date)
+	pdf.SetModificationDate(r.Date)
+}
+
+var thaiMonthAbbreviations = []string{"ม.ค.", "ก.พ.", "มี.ค.", "เม.ย.", "พ.ค.", "มิ.ย.", "ก.ค.", "ส.ค.", "ก.ย.", "ต.ค.", "พ.ย.", "ธ.ค."}
+
+// thaiDate formats t as a short Thai date in the Buddhist era, e.g.
+// "28 มิ.ย. 2566".
+func thaiDate(t time.Time) string {
+	return strconv.Itoa(t.Day()) + " " + thaiMonthAbbreviations[t.Month()-1] + " " + strconv.Itoa(t.Year()+543)
+}
+
+// EmissionSource is a source of the greenhouse gas inventory with its
+// emissions in tCO2e.
+type EmissionSource struct {
+	Scope     int
+	Name      string
+	Emissions float64
+}
+
+// Inventory is the greenhouse gas inventory of a reporting year.
+type Inventory struct {
+	Year    int
+	Sources []EmissionSource
+}
+
+// reportInventory holds the emissions reported in section 5, consolidated
+// from the records of each facility.
+var reportInventory = reportData.inventory()
+
+// baseInventory holds the base year emissions that sections 5.5 and 6.3
+// compare against, recalculated when the changes since call for it.
+var baseInventory = reportData.BaseYear.restated()
+
+// ScopeTotal returns the emissions of scope, or of all scopes when scope is
+// zero.
+func (inv Inventory) ScopeTotal(scope int) float64 {
+	total := 0.0
+	for _, source := range inv.Sources {
+		if scope == 0 || source.Scope == scope {
+			total += source.Emissions
+		}
+	}
+	return total
+}
+
+// scopeName returns the Thai name of scope, e.g. "ประเภทที่ 1".
+func scopeName(scope int) string {
+	return "ประเภทที่ " + strconv.Itoa(scope)
+}
+
+// formatNumber formats v with decimals digits and thousands separators,
+// e.g. "1,269,288.86".
+func formatNumber(v float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteString("-")
+	}
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(",")
+		}
+		b.WriteRune(r)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+	return b.String()
+}
+
+// pendingData stands in for a value that is still being collected.
+const pendingData = "รอข้อมูล"
+
+// formatFactor formats an emission factor with up to 4 decimals, e.g.
+// "0.4999".
+func formatFactor(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(formatNumber(v, 4), "0"), ".")
+}
+
+// formatPercent formats a fraction as a percentage without trailing zeros,
+// e.g. "5" for 0.05.
+func formatPercent(fraction float64) string {
+	return strings.TrimSuffix(strings.TrimRight(formatNumber(100*fraction, 2), "0"), ".")
+}
+
+// scopeChart returns a donut chart of the share of each scope.
+func (inv Inventory) scopeChart() PieChart {
+	chart := PieChart{Hole: 0.5, Unit: "tCO2e"}
+	for scope := 1; scope <= 3; scope++ {
+		chart.Values = append(chart.Values, ChartValue{Label: scopeName(scope), Value: inv.ScopeTotal(scope)})
+	}
+	return chart
+}
+
+// sourceChart returns a horizontal bar chart of the emissions of each
+// source.
+func (inv Inventory) sourceChart() BarChart {
+	chart := BarChart{Horizontal: true, Unit: "tCO2e", Series: []ChartSeries{{Label: "tCO2e"}}}
+	for _, source := range inv.Sources {
+		chart.Categories = append(chart.Categories, source.Name)
+		chart.Series[0].Values = append(chart.Series[0].Values, source.Emissions)
+	}
+	return chart
+}
+
+// hasScope reports whether the inventory has any source in scope.
+func (inv Inventory) hasScope(scope int) bool {
+	for _, source := range inv.Sources {
+		if source.Scope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// source returns the source of scope named name, matched as in section 3.2,
+// see sourceKey.
+func (inv Inventory) source(scope int, name string) (EmissionSource, bool) {
+	for _, source := range inv.Sources {
+		if source.Scope == scope && sourceKey(source.Name) == sourceKey(name) {
+			return source, true
+		}
+	}
+	return EmissionSource{}, false
+}
+
+// comparisonRow is a line of the comparison of two inventories. A value
+// is missing when the inventory does not cover the scope or source.
+type comparisonRow struct {
+	label               string
+	scope               bool // a scope total rather than a source
+	base, current       float64
+	hasBase, hasCurrent bool
+}
+
+// compareInventories lines up base and current by scope and by source,
+// each scope total followed by its sources in the order of current and
+// then those found only in base.
+func compareInventories(base, current Inventory) []comparisonRow {
+	var rows []comparisonRow
+	for scope := 1; scope <= 3; scope++ {
+		rows = append(rows, comparisonRow{
+			label:      scopeName(scope),
+			scope:      true,
+			base:       base.ScopeTotal(scope),
+			current:    current.ScopeTotal(scope),
+			hasBase:    base.hasScope(scope),
+			hasCurrent: current.hasScope(scope),
+		})
+
+		var names []string
+		for _, inv := range []Inventory{current, base} {
+			for _, source := range inv.Sources {
+				if source.Scope == scope && !slices.Contains(names, source.Name) {
+					names = append(names, source.Name)
+				}
+			}
+		}
+		for _, name := range names {
+			b, hasBase := base.source(scope, name)
+			c, hasCurrent := current.source(scope, name)
+			rows = append(rows, comparisonRow{
+				label:      name,
+				base:       b.Emissions,
+				current:    c.Emissions,
+				hasBase:    hasBase,
+				hasCurrent: hasCurrent,
+			})
+		}
+	}
+	return rows
+}
+
+// comparisonTable returns the comparison of base with inv as table data:
+// the values of both years with the change in tCO2e and percent.
+func (inv Inventory) comparisonTable(base Inventory) [][]string {
+	data := [][]string{{
+		"ขอบเขต / แหล่งปล่อยก๊าซเรือนกระจก",
+		"ปีฐาน " + strconv.Itoa(base.Year) + " (Ton CO2e)",
+		"ปี " + strconv.Itoa(inv.Year) + " (Ton CO2e)",
+		"เปลี่ยนแปลง (Ton CO2e)",
+		"เปลี่ยนแปลง (%)",
+	}}
+	value := func(v float64, ok bool) string {
+		if !ok {
+			return "-"
+		}
+		return formatNumber(v, 2)
+	}
+	for _, row := range compareInventories(base, inv) {
+		label := row.label
+		if !row.scope {
+			label = "   - " + label
+		}
+		change, percent := "-", "-"
+		if row.hasBase && row.hasCurrent {
+			change = signedNumber(row.current-row.base, 2)
+			if row.base != 0 {
+				percent = signedNumber(100*(row.current-row.base)/row.base, 2) + "%"
+			}
+		}
+		data = append(data, []string{label, value(row.base, row.hasBase), value(row.current, row.hasCurrent), change, percent})
+	}
+	return data
+}
+
+// comparisonChart returns a grouped bar chart of the scope totals of base
+// and inv.
+func (inv Inventory) comparisonChart(base Inventory) BarChart {
+	chart := BarChart{Grouped: true, Unit: "tCO2e"}
+	for _, year := range []struct {
+		label string
+		inv   Inventory
+	}{
+		{"ปีฐาน " + strconv.Itoa(base.Year), base},
+		{"ปี " + strconv.Itoa(inv.Year), inv},
+	} {
+		series := ChartSeries{Label: year.label}
+		for scope := 1; scope <= 3; scope++ {
+			series.Values = append(series.Values, year.inv.ScopeTotal(scope))
+		}
+		chart.Series = append(chart.Series, series)
+	}
+	for scope := 1; scope <= 3; scope++ {
+		chart.Categories = append(chart.Categories, scopeName(scope))
+	}
+	return chart
+}
+
+// signedNumber formats v like formatNumber with a "+" on increases.
+func signedNumber(v float64, decimals int) string {
+	s := formatNumber(v, decimals)
+	if v > 0 && strings.Trim(s, "0.,") != "" {
+		return "+" + s
+	}
+	return s
+}
+
+// Report is the content of the report that validation checks. Scope 2 is
+// counted location-based in its inventory, see inventory; the market-based
+// scope 2 is shown next to it in sections 3.2.8 and 5.
+type Report struct {
+	Info          ReportInfo
+	Year          int
+	Consolidation Consolidation
+	Facilities    []Facility
+	// Records are the emissions recorded as totals, e.g. of the Scope 3
+	// categories.
+	Records []ActivityRecord
+	// Energy is the electricity, steam and heat bought, counted in scope 2.
+	Energy []EnergyPurchase
+	// Fuels tags the scope 1 fuels fossil or biogenic, so that biogenic CO2
+	// is reported apart in section 5.4.
+	Fuels       []Fuel
+	FuelRecords []FuelRecord
+	// Materiality is the threshold of section 2.9 as a fraction, e.g. 0.05.
+	Materiality float64
+	// Denominators give the carbon intensities of section 5.5.
+	Denominators []Denominator
+	// Activities are the activities of each facility in section 3.1.4.
+	Activities []FacilityActivities
+	// Sources are the scope 1 and 2 emission sources of section 3.2, and
+	// Scope3 the Scope 3 categories.
+	Sources []ScopeSource
+	Scope3  []Scope3Entry
+	// Credits are the projects and certificates of section 3.2.8.
+	Credits []Credit
+	// GridFactor is the emission factor of grid electricity location-based
+	// and ResidualFactor that of the residual mix market-based, both in
+	// kgCO2e/kWh.
+	GridFactor     float64
+	ResidualFactor float64
+	// Monitoring is how the activity data of the sources is monitored in
+	// section 4.
+	Monitoring []Monitoring
+	// BaseYear is the base year of section 6.
+	BaseYear BaseYear
+}
+
+// FacilityActivities lists the scope 1 and 2 activities of the facility
+// with code Facility, as in section 3.1.4. Scope 3 activities come from the
+// Scope 3 categories.
+type FacilityActivities struct {
+	Facility   string
+	Activities [2][]string // by scope, scope 1 first
+}
+
+// ScopeSource is an emission source of scope 1 or 2 listed in a table of
+// section 3.2.
+// Internal and External hold the marks of the "ใช้ภายใน" and
+// "จำหน่ายภายนอก" columns. Its significance is computed from the inventory
+// unless an analyst overrides it, giving the reason in Justification.
+type ScopeSource struct {
+	Scope         int
+	Category      string // e.g. "Mobile Combustion"
+	Facility      string
+	Name          string
+	Location      string
+	Internal      string
+	External      string
+	Override      string // "มาก" or "น้อย", empty to use the computed one
+	Justification string
+}
+
+// Where the activity data of a source comes from, as marked in the tables
+// of section 4.
+const (
+	OriginMeasured  = "measured"
+	OriginPayment   = "payment"
+	OriginEstimated = "estimated"
+)
+
+// Monitoring is how the activity data of a source is monitored, as listed
+// in the tables of section 4.
+type Monitoring struct {
+	Scope    int
+	Source   string
+	Units    []string // the activity data measured, e.g. "ลิตร"
+	Points   []string // where it is measured or recorded
+	Origin   string   // one of the Origin constants
+	Evidence []string
+	EFSource string
+}
+
+// SummaryTable is a table of section 5: the emissions of each source of a
+// scope and the total in tCO2e, before they are formatted. See
+// summaryTables.
+type SummaryTable struct {
+	Scope int
+	Rows  []SummaryRow
+	Total float64
+}
+
+// SummaryRow is a line of a SummaryTable. Missing is set when the inventory
+// has no emissions for the source.
+type SummaryRow struct {
+	Source    string
+	Emissions float64
+	Missing   bool
+}
+
+// reportData is the content of this report.
+var reportData = Report{
+	Info:          reportInfo,
+	Year:          2565,
+	Consolidation: OperationalControl,
+	Facilities: []Facility{
+		{Code: "LR1", Name: "บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 1", OperationalControl: true, FinancialControl: true, EquityShare: 1},
+		{Code: "LR2", Name: "บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 2", OperationalControl: true, FinancialControl: true, EquityShare: 1},
+		{Code: "LR3", Name: "บริษัท เบทาโกร จำกัด (มหาชน)  โรงงานลพบุรี 3", OperationalControl: true, FinancialControl: true, EquityShare: 1},
+	},
+	// The activity data of this report was recorded for the three plants
+	// together.
+	Records: []ActivityRecord{
+		{Scope: 3, Source: PurchasedGoods.Name(), Emissions: 1269288.86},
+		{Scope: 3, Source: FuelAndEnergy.Name(), Emissions: 8018.48},
+		{Scope: 3, Source: UpstreamTransport.Name(), Emissions: 83586.95},
+		{Scope: 3, Source: WasteGenerated.Name(), Emissions: 263.95},
+		{Scope: 3, Source: DownstreamTransport.Name(), Emissions: 19068.44},
+		// The electricity is the reported total until the kWh bought are
+		// taken from the monthly PEA invoices and recorded in Energy.
+		{Scope: 2, Source: "การใช้ไฟฟ้า", Emissions: 28252.52},
+	},
+	Fuels: []Fuel{
+		{Name: "น้ำมันดีเซล", Origin: Fossil},
+	},
+	FuelRecords: []FuelRecord{
+		{
+			Category: "Mobile Combustion",
+			Source:   "น้ำมันดีเซลรถยนต์",
+			Fuel:     "น้ำมันดีเซล",
+			Gases:    map[Gas]float64{CO2: 316.86, FossilCH4: 0.53, N2O: 31.45},
+		},
+	},
+	Materiality: 0.05,
+	// The values of the denominators are still to be taken from the
+	// production, accounting and personnel records; section 5.5 shows them
+	// as pending until then.
+	Denominators: []Denominator{
+		{Name: "ปริมาณการผลิตอาหารสัตว์", Unit: "ton product", Numerator: "kgCO2e"},
+		{Name: "รายได้", Unit: "million THB", Numerator: "tCO2e"},
+		{Name: "พื้นที่อาคาร", Unit: "m2", Numerator: "kgCO2e"},
+		{Name: "จำนวนพนักงาน", Unit: "employee", Numerator: "tCO2e"},
+	},
+	Activities: []FacilityActivities{{
+		Facility: "LR1",
+		Activities: [2][]string{
+			{
+				"1. การเผาไหม้น้ำมันดีเซลรถยนต์",
+				"2. การเผาไหม้น้ำมันเบนซีนรถยนต์",
+				"3. การเผาไหม้ก๊าซ LPG สำหรับรถยนต์",
+				"4. การเผาไหม้ก๊าซ NGV สำหรับรถยนต",
+				"5. การเผาไหม้น้ำมันดีเซล generator + fire pump",
+				"6. การเผาไหม้น้ำมันดีเซลเครื่องตัดหญ้า",
+				"7. การเผาไหม้น้ำมันเตา C Boiler และการอบข้าวโพด",
+			},
+			{"1. การใช้ไฟฟ้า"},
+		},
+	}},
+	Sources: []ScopeSource{
+		{Scope: 1, Category: "Mobile Combustion", Facility: "LR1,2,3", Name: "1. น้ำมันดีเซลรถยนต์", Location: "-", Internal: "X"},
+		{Scope: 2, Category: "Purchased Electricity", Facility: "LR1,2,3", Name: "1. การใช้ไฟฟ้า", Location: "-", Internal: "X"},
+	},
+	Scope3: []Scope3Entry{
+		{Category: PurchasedGoods, Included: true, Facility: "LR1,2,3", Location: "-", Internal: "X"},
+		{Category: CapitalGoods, Reason: "ยังไม่มีข้อมูลกิจกรรมของสินค้าประเภททุนที่เพียงพอ"},
+		{Category: FuelAndEnergy, Included: true, Facility: "LR1,2,3", Location: "-", Internal: "X"},
+		{Category: UpstreamTransport, Included: true, Facility: "LR1,2,3", Location: "-", Internal: "X"},
+		{Category: WasteGenerated, Included: true, Facility: "LR1,2,3", Location: "-", Internal: "X"},
+		{Category: BusinessTravel, Reason: "ยังไม่มีระบบเก็บข้อมูลการเดินทางเพื่อธุรกิจ"},
+		{Category: EmployeeCommuting, Reason: "ยังไม่มีระบบเก็บข้อมูลการเดินทางของพนักงาน"},
+		{Category: UpstreamLeasedAssets, Reason: "องค์กรไม่มีสินทรัพย์ที่เช่าใช้"},
+		{Category: DownstreamTransport, Included: true, Facility: "LR1,2,3", Location: "-", External: "X"},
+		{Category: ProcessingOfSoldProducts, Reason: "ไม่สามารถติดตามการแปรรูปอาหารสัตว์ของลูกค้าได้"},
+		{Category: UseOfSoldProducts, Reason: "อาหารสัตว์ไม่ใช้พลังงานในการใช้งาน"},
+		{Category: EndOfLifeTreatment, Reason: "ไม่สามารถติดตามการจัดการบรรจุภัณฑ์หลังการใช้ของลูกค้าได้"},
+		{Category: DownstreamLeasedAssets, Reason: "องค์กรไม่มีสินทรัพย์ที่ให้ผู้อื่นเช่า"},
+		{Category: Franchises, Reason: "องค์กรไม่มีธุรกิจแฟรนไชส์"},
+		{Category: Investments, Reason: "ไม่อยู่ในขอบเขตการควบคุมการดำเนินงาน"},
+	},
+	GridFactor: 0.4999,
+	Monitoring: []Monitoring{
+		{
+			Scope:    1,
+			Source:   "น้ำมันดีเซลรถยนต์",
+			Units:    []string{"ลิตร", "บาท"},
+			Points:   []string{"แผนกยานยนต์", "แผนกทรัพยากรมนุษย์"},
+			Origin:   OriginPayment,
+			Evidence: []string{"รายงานสรุป Fleet card", "1. ยอดเบิกเงินจาก SAP 2. ราคาน้ำมันเฉลี่ยรายเดือน"},
+			EFSource: "CFO TGO EF",
+		},
+		{
+			Scope:    2,
+			Source:   "การใช้ไฟฟ้า",
+			Units:    []string{"kWh"},
+			Points:   []string{"แผนกพลังงาน"},
+			Origin:   OriginMeasured,
+			Evidence: []string{"1. รายงานการใช้ไฟฟ้า (จริง) rate 115 kv ประจำเดือนของโรงงาน 2. หนังสือแจ้งค่าไฟฟ้าจากการไฟฟ้าส่วนภูมิภาค"},
+			EFSource: "CFO TGO EF",
+		},
+		{
+			Scope:    3,
+			Source:   "1. Purchased goods and services",
+			Units:    []string{"กก."},
+			Points:   []string{"แผนกคลังวัตถุดิบ"},
+			Origin:   OriginMeasured,
+			Evidence: []string{"1. ข้อมูลการรับเข้าจากระบบ SAP"},
+			EFSource: "CFO TGO EF",
+		},
+		{
+			Scope:    3,
+			Source:   "3. Fuel- and energy-related activities not included in scope 1 or 2",
+			Units:    []string{"ลิตร", "kWh"},
+			Points:   []string{"แผนกยานยนต์", "แผนกพลังงาน"},
+			Origin:   OriginPayment,
+			Evidence: []string{"รายงานสรุป Fleet card", "หนังสือแจ้งค่าไฟฟ้าจากการไฟฟ้าส่วนภูมิภาค"},
+			EFSource: "CFO TGO EF",
+		},
+		{
+			Scope:    3,
+			Source:   "4. Upstream transportation and distribution",
+			Units:    []string{"ตัน-กม."},
+			Points:   []string{"แผนกจัดซื้อ"},
+			Origin:   OriginPayment,
+			Evidence: []string{"1. ใบแจ้งหนี้ค่าขนส่งวัตถุดิบ 2. ข้อมูลการรับเข้าจากระบบ SAP"},
+			EFSource: "CFO TGO EF",
+		},
+		{
+			Scope:    3,
+			Source:   "5. Waste generated in operations",
+			Units:    []string{"กก."},
+			Points:   []string{"แผนกสิ่งแวดล้อม"},
+			Origin:   OriginMeasured,
+			Evidence: []string{"ใบกำกับการขนส่งของเสีย (Manifest)"},
+			EFSource: "CFO TGO EF",
+		},
+		{
+			Scope:    3,
+			Source:   "9. Downstream transportation and distribution",
+			Units:    []string{"ตัน-กม."},
+			Points:   []string{"แผนกจัดส่งสินค้า"},
+			Origin:   OriginPayment,
+			Evidence: []string{"1. ใบแจ้งหนี้ค่าขนส่งสินค้า 2. ข้อมูลการจัดส่งจากระบบ SAP"},
+			EFSource: "CFO TGO EF",
+		},
+	},
+	BaseYear: BaseYear{
+		Period: "มกราคม ถึง ธันวาคม 2564",
+		Original: Inventory{
+			Year: 2564,
+			Sources: []EmissionSource{
+				{Scope: 1, Name: "น้ำมันดีเซลรถยนต์", Emissions: 298.64},
+			},
+		},
+		Threshold: 0.05,
+		Coverage:  "ปีฐาน 2564 ทวนสอบเฉพาะประเภทที่ 1 ปริมาณการปล่อยก๊าซเรือนกระจกประเภทที่ 2 และ 3 จึงไม่นำมาเปรียบเทียบกับปีฐาน",
+	},
+}
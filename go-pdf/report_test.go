@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		v        float64
+		decimals int
+		want     string
+	}{
+		{1269288.86, 2, "1,269,288.86"},
+		{28252.52, 0, "28,253"},
+		{999, 0, "999"},
+		{1000, 2, "1,000.00"},
+		{-1234.5, 1, "-1,234.5"},
+		{-0.001, 2, "0.00"},
+		{0, 2, "0.00"},
+	}
+	for _, tt := range tests {
+		if got := formatNumber(tt.v, tt.decimals); got != tt.want {
+			t.Errorf("formatNumber(%v, %d) = %q, want %q", tt.v, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestSourceKey(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"1. น้ำมันดีเซลรถยนต์", "น้ำมันดีเซลรถยนต์"},
+		{" 1.Purchased goods  and services ", "purchased goods and services"},
+	}
+	for _, tt := range tests {
+		if sourceKey(tt.a) != sourceKey(tt.b) {
+			t.Errorf("sourceKey(%q) = %q, sourceKey(%q) = %q", tt.a, sourceKey(tt.a), tt.b, sourceKey(tt.b))
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EnergyKind is the kind of energy bought from others whose generation is
+// counted in scope 2.
+type EnergyKind int
+
+const (
+	Electricity EnergyKind = iota
+	Steam
+	Heat
+)
+
+var energyKindNames = []string{
+	Electricity: "ไฟฟ้า",
+	Steam:       "ไอน้ำ",
+	Heat:        "ความร้อน",
+}
+
+func (k EnergyKind) String() string {
+	if k < Electricity || k > Heat {
+		return "EnergyKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return energyKindNames[k]
+}
+
+// Unit returns the unit the energy is bought in.
+func (k EnergyKind) Unit() string {
+	if k == Electricity {
+		return "kWh"
+	}
+	return "GJ"
+}
+
+// EnergyPurchase is energy of Kind bought for Source at a facility, in the
+// unit of the kind and before consolidation. SupplierFactor is the emission
+// factor of the supplier in kgCO2e per unit, or zero when the supplier has
+// none. Facility is empty for data recorded for all facilities together.
+//
+// Electricity is counted at the grid factor of the report location-based
+// and at the supplier factor, or else the residual mix after RECs,
+// market-based. Steam and heat have no grid and are counted at the
+// supplier factor by both methods.
+type EnergyPurchase struct {
+	Facility       string
+	Source         string
+	Kind           EnergyKind
+	Quantity       float64
+	SupplierFactor float64
+}
+
+// residualFactor returns the emission factor in kgCO2e/kWh of the
+// electricity that neither a supplier factor nor RECs cover: the residual
+// mix, or the grid factor where no residual mix is published.
+func (r Report) residualFactor() float64 {
+	if r.ResidualFactor > 0 {
+		return r.ResidualFactor
+	}
+	return r.GridFactor
+}
+
+// locationBased returns the location-based emissions of p in tCO2e, before
+// consolidation.
+func (r Report) locationBased(p EnergyPurchase) float64 {
+	if p.Kind == Electricity {
+		return p.Quantity * r.GridFactor / 1000
+	}
+	return p.Quantity * p.SupplierFactor / 1000
+}
+
+// marketBased returns the consolidated market-based emissions of each
+// purchase in tCO2e, and the emissions the retired RECs avoid. RECs cover
+// the grid electricity without a supplier factor in the order it is
+// recorded.
+func (r Report) marketBased() (emissions []float64, certificates float64) {
+	remaining := r.retiredCertificates()
+	for _, p := range r.Energy {
+		share, _ := r.consolidationShare(p.Facility)
+		quantity := share * p.Quantity
+		if p.Kind != Electricity || p.SupplierFactor > 0 {
+			emissions = append(emissions, quantity*p.SupplierFactor/1000)
+			continue
+		}
+		covered := min(quantity, remaining)
+		remaining -= covered
+		emissions = append(emissions, (quantity-covered)*r.residualFactor()/1000)
+		certificates += covered * r.residualFactor() / 1000
+	}
+	return emissions, certificates
+}
+
+// scope2 returns the consolidated scope 2 emissions by the market-based
+// method when market is true, or the location-based one when it is false.
+// The scope 2 records given as totals count the same under both.
+func (r Report) scope2(market bool) float64 {
+	total := 0.0
+	for _, record := range r.Records {
+		if record.Scope == 2 {
+			share, _ := r.consolidationShare(record.Facility)
+			total += share * record.Emissions
+		}
+	}
+	if market {
+		emissions, _ := r.marketBased()
+		for _, e := range emissions {
+			total += e
+		}
+		return total
+	}
+	for _, p := range r.Energy {
+		share, _ := r.consolidationShare(p.Facility)
+		total += share * r.locationBased(p)
+	}
+	return total
+}
+
+// scope2Row is a line of the table of section 5.2: the consolidated energy
+// bought for a source at a facility with its emissions in tCO2e. Unit is
+// empty for the scope 2 records given as totals.
+type scope2Row struct {
+	facility, source string
+	quantity         float64
+	unit             string
+	location, market float64
+}
+
+// scope2Rows returns the lines of the table of section 5.2: the energy
+// purchases followed by the scope 2 records.
+func (r Report) scope2Rows() []scope2Row {
+	var rows []scope2Row
+	market, _ := r.marketBased()
+	for i, p := range r.Energy {
+		share, _ := r.consolidationShare(p.Facility)
+		rows = append(rows, scope2Row{
+			facility: p.Facility,
+			source:   strings.TrimSpace(p.Source),
+			quantity: share * p.Quantity,
+			unit:     p.Kind.Unit(),
+			location: share * r.locationBased(p),
+			market:   market[i],
+		})
+	}
+	for _, record := range r.Records {
+		if record.Scope != 2 {
+			continue
+		}
+		share, _ := r.consolidationShare(record.Facility)
+		emissions := share * record.Emissions
+		rows = append(rows, scope2Row{source: strings.TrimSpace(record.Source), location: emissions, market: emissions})
+	}
+	return rows
+}
+
+// scope2Table returns the table of section 5.2: the consolidated energy
+// bought for each source with its location-based and market-based
+// emissions. The energy of the scope 2 records given as totals is pending.
+func (r Report) scope2Table() [][]string {
+	data := [][]string{{
+		"แหล่งปล่อยก๊าซเรือนกระจก",
+		"ปริมาณที่ซื้อ",
+		"หน่วย",
+		"Location-based (Ton CO2e)",
+		"Market-based (Ton CO2e)",
+	}}
+	for _, row := range r.scope2Rows() {
+		name, quantity, unit := row.source, "-", "-"
+		if row.facility != "" {
+			name += " (" + row.facility + ")"
+		}
+		if row.unit != "" {
+			quantity, unit = formatNumber(row.quantity, 0), row.unit
+		} else {
+			quantity = pendingData
+		}
+		data = append(data, []string{name, quantity, unit, formatNumber(row.location, 2), formatNumber(row.market, 2)})
+	}
+	return append(data, []string{"รวมทั้งหมด", "", "", formatNumber(r.scope2(false), 2), formatNumber(r.scope2(true), 2)})
+}
+
+// scope2Summary returns the table of section 5.2 as a SummaryTable of the
+// location-based emissions of each source, so that it is validated like the
+// other tables of section 5.
+func (r Report) scope2Summary() SummaryTable {
+	table := SummaryTable{Scope: 2, Total: r.scope2(false)}
+	for _, row := range r.scope2Rows() {
+		table.Rows = append(table.Rows, SummaryRow{Source: row.source, Emissions: row.location})
+	}
+	return table
+}
+
+// scope2Notes returns the notes under the table of section 5.2: the
+// emission factors of each method.
+func (r Report) scope2Notes() *TableNotes {
+	notes := &TableNotes{Title: "หมายเหตุ"}
+	notes.Add("Location-based คิดไฟฟ้าที่ค่า EF ของระบบไฟฟ้า " + formatFactor(r.GridFactor) + " kgCO2e/kWh")
+	text := "Market-based คิดไฟฟ้าที่ค่า EF ของผู้จำหน่ายไฟฟ้าเมื่อมี หักส่วนที่มีสิทธิพลังงานหมุนเวียน (REC) รองรับ และคิดส่วนที่เหลือที่ค่า EF "
+	if r.ResidualFactor > 0 {
+		text += "Residual mix " + formatFactor(r.ResidualFactor) + " kgCO2e/kWh"
+	} else {
+		text += "ของระบบไฟฟ้า เนื่องจากไม่มีการเผยแพร่ค่า Residual mix"
+	}
+	notes.Add(text)
+	notes.Add("ไอน้ำและความร้อนคิดที่ค่า EF ของผู้จำหน่ายทั้งสองวิธี")
+	for _, record := range r.Records {
+		if record.Scope == 2 {
+			notes.Add("“" + pendingData + "” หมายถึง ยังรอปริมาณที่ซื้อตามหลักฐานการชำระเงิน จึงรายงานปริมาณการปล่อยก๊าซเรือนกระจกตามที่บันทึกไว้ทั้งสองวิธี")
+			break
+		}
+	}
+	return notes
+}
+
+// scopeSummaryTable returns the summary of section 5: the emissions of each
+// scope, with scope 2 and the totals by both methods.
+func (r Report) scopeSummaryTable() [][]string {
+	inv := r.inventory()
+	scope1, scope3 := inv.ScopeTotal(1), inv.ScopeTotal(3)
+	location, market := r.scope2(false), r.scope2(true)
+	return [][]string{
+		{"ขอบเขตการดำเนินงาน", "Location-based (Ton CO2e)", "Market-based (Ton CO2e)"},
+		{scopeName(1), formatNumber(scope1, 2), formatNumber(scope1, 2)},
+		{scopeName(2), formatNumber(location, 2), formatNumber(market, 2)},
+		{scopeName(3), formatNumber(scope3, 2), formatNumber(scope3, 2)},
+		{"รวมประเภทที่ 1+2", formatNumber(scope1+location, 2), formatNumber(scope1+market, 2)},
+		{"รวมทั้งหมด", formatNumber(scope1+location+scope3, 2), formatNumber(scope1+market+scope3, 2)},
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Scope3Category is one of the 15 categories of the GHG Protocol Corporate
+// Value Chain (Scope 3) Standard, numbered as in the standard.
+type Scope3Category int
+
+const (
+	PurchasedGoods Scope3Category = iota + 1
+	CapitalGoods
+	FuelAndEnergy
+	UpstreamTransport
+	WasteGenerated
+	BusinessTravel
+	EmployeeCommuting
+	UpstreamLeasedAssets
+	DownstreamTransport
+	ProcessingOfSoldProducts
+	UseOfSoldProducts
+	EndOfLifeTreatment
+	DownstreamLeasedAssets
+	Franchises
+	Investments
+)
+
+var scope3CategoryNames = []string{
+	PurchasedGoods:           "Purchased goods and services",
+	CapitalGoods:             "Capital goods",
+	FuelAndEnergy:            "Fuel- and energy-related activities not included in scope 1 or 2",
+	UpstreamTransport:        "Upstream transportation and distribution",
+	WasteGenerated:           "Waste generated in operations",
+	BusinessTravel:           "Business travel",
+	EmployeeCommuting:        "Employee commuting",
+	UpstreamLeasedAssets:     "Upstream leased assets",
+	DownstreamTransport:      "Downstream transportation and distribution",
+	ProcessingOfSoldProducts: "Processing of sold products",
+	UseOfSoldProducts:        "Use of sold products",
+	EndOfLifeTreatment:       "End-of-life treatment of sold products",
+	DownstreamLeasedAssets:   "Downstream leased assets",
+	Franchises:               "Franchises",
+	Investments:              "Investments",
+}
+
+// Name returns the name of the category in the standard, which is also the
+// name of its source in the inventory.
+func (c Scope3Category) Name() string {
+	if c < PurchasedGoods || c > Investments {
+		return "Category " + strconv.Itoa(int(c))
+	}
+	return scope3CategoryNames[c]
+}
+
+// String returns the numbered name, e.g. "4. Upstream transportation and
+// distribution".
+func (c Scope3Category) String() string {
+	return strconv.Itoa(int(c)) + ". " + c.Name()
+}
+
+// upstream reports whether the category is upstream of the organisation:
+// categories 1 to 8 are, 9 to 15 are downstream.
+func (c Scope3Category) upstream() bool {
+	return c <= UpstreamLeasedAssets
+}
+
+// Scope3Entry says whether a category is included in the inventory. An
+// included category is listed in section 3.2.6 at Facility and Location,
+// with the marks of its "ใช้ภายใน" and "จำหน่ายภายนอก" columns in Internal
+// and External; an excluded one is listed in the note under section 3.1.4
+// with Reason.
+type Scope3Entry struct {
+	Category Scope3Category
+	Included bool
+	Reason   string
+	Facility string
+	Location string
+	Internal string
+	External string
+}
+
+// source returns the entry as a source of section 3.2.6.
+func (e Scope3Entry) source() ScopeSource {
+	category := "Downstream"
+	if e.Category.upstream() {
+		category = "Upstream"
+	}
+	return ScopeSource{
+		Scope:    3,
+		Category: category,
+		Facility: e.Facility,
+		Name:     e.Category.String(),
+		Location: e.Location,
+		Internal: e.Internal,
+		External: e.External,
+	}
+}
+
+// scope3Sources returns the included categories as sources of section
+// 3.2.6, grouped into upstream and downstream.
+func (r Report) scope3Sources() []ScopeSource {
+	var sources []ScopeSource
+	for _, upstream := range []bool{true, false} {
+		for _, entry := range r.Scope3 {
+			if !entry.Included || entry.Category.upstream() != upstream {
+				continue
+			}
+			sources = append(sources, entry.source())
+		}
+	}
+	return sources
+}
+
+// sources returns the sources of scope listed in section 3.2; those of
+// scope 3 come from the Scope 3 categories.
+func (r Report) sources(scope int) []ScopeSource {
+	if scope == 3 {
+		return r.scope3Sources()
+	}
+	var sources []ScopeSource
+	for _, source := range r.Sources {
+		if source.Scope == scope {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// activityTable returns the table of section 3.1.4: the scope 1 and 2
+// activities of each facility next to the included Scope 3 categories,
+// which the organisation reports as a whole.
+func (r Report) activityTable() [][]string {
+	data := [][]string{{"Facility", "Scope 1", "Scope 2", "Scope 3"}}
+	var scope3 []string
+	for _, source := range r.scope3Sources() {
+		scope3 = append(scope3, source.Name)
+	}
+	for i, facility := range r.Activities {
+		columns := [][]string{{r.facilityName(facility.Facility)}, facility.Activities[0], facility.Activities[1], nil}
+		if i == 0 {
+			columns[3] = scope3
+		}
+		rows := 0
+		for _, column := range columns {
+			rows = max(rows, len(column))
+		}
+		for j := 0; j < rows; j++ {
+			row := make([]string, len(columns))
+			for c, column := range columns {
+				if j < len(column) {
+					row[c] = column[j]
+				}
+			}
+			data = append(data, row)
+		}
+	}
+	return data
+}
+
+// activityNotes returns the note under the table of section 3.1.4 that
+// lists the Scope 3 categories not included in the monitoring.
+func (r Report) activityNotes() *TableNotes {
+	notes := &TableNotes{Title: "หมายเหตุ *กิจกรรมขององค์กรใน Scope 3 ที่ไม่รวมไว้ในการติดตามผล"}
+	for _, entry := range r.Scope3 {
+		if entry.Included {
+			continue
+		}
+		text := entry.Category.String()
+		if reason := strings.TrimSpace(entry.Reason); reason != "" {
+			text += " เนื่องจาก " + reason
+		}
+		notes.Add(text)
+	}
+	return notes
+}
+
+// scope3Summary returns the emissions of the included categories as the
+// table of section 5.3, totalled from the inventory.
+func (r Report) scope3Summary() SummaryTable {
+	table := SummaryTable{Scope: 3}
+	for _, source := range r.scope3Sources() {
+		emissions, ok := r.inventorySource(3, source.Name)
+		table.Rows = append(table.Rows, SummaryRow{Source: source.Name, Emissions: emissions.Emissions, Missing: !ok})
+		table.Total += emissions.Emissions
+	}
+	return table
+}
+
+// summaryTables returns the tables of sections 5.1 to 5.3 as
+// SummaryTables, by scope.
+func (r Report) summaryTables() []SummaryTable {
+	return []SummaryTable{r.scope1Summary(), r.scope2Summary(), r.scope3Summary()}
+}
+
+// data returns the table as table data with a header and a total row.
+func (t SummaryTable) data() [][]string {
+	data := [][]string{{"แหล่งปล่อยก๊าซเรือนกระจก", "ปริมาณการปล่อย GHG (Ton CO2e)"}}
+	for _, row := range t.Rows {
+		emissions := "-"
+		if !row.Missing {
+			emissions = formatNumber(row.Emissions, 2)
+		}
+		data = append(data, []string{row.Source, emissions})
+	}
+	return append(data, []string{"รวมทั้งหมด", formatNumber(t.Total, 2)})
+}
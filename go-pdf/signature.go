@@ -0,0 +1,773 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Signer signs reports with a PKCS#7 detached signature, the
+// adbe.pkcs7.detached format that PDF readers verify. The certificate and
+// key are read from PEM or from a PKCS#12 file, and a certificate for
+// testing can be made with
+//
+//	openssl req -x509 -newkey rsa:2048 -nodes -days 365 -subj "/CN=Test Verifier" -keyout signer.pem -out signer.pem
+type Signer struct {
+	Key crypto.Signer
+	// Chain is the signing certificate followed by the certificates that
+	// issued it.
+	Chain  []*x509.Certificate
+	Reason string
+}
+
+// SignatureField is where a visible signature goes: a page, counted from
+// 1, and a rectangle in PDF user space.
+type SignatureField struct {
+	Page int
+	Rect [4]float64
+}
+
+const (
+	signatureBoxWidth  = 90.0
+	signatureBoxHeight = 30.0
+	// signatureReserve is the room left for the signature besides the
+	// certificates it carries.
+	signatureReserve = 4096
+)
+
+var (
+	oidData            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSHA256          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+// loadSigner reads the certificates from certPath and the private key
+// from keyPath, which may be the same PEM file. A certPath ending in .p12
+// or .pfx is a PKCS#12 file that holds both, opened with password.
+func loadSigner(certPath, keyPath, password string) (*Signer, error) {
+	if ext := strings.ToLower(filepath.Ext(certPath)); ext == ".p12" || ext == ".pfx" {
+		return loadPKCS12Signer(certPath, password)
+	}
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM := certPEM
+	if keyPath != "" && keyPath != certPath {
+		if keyPEM, err = os.ReadFile(keyPath); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Signer{}
+	for rest := certPEM; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		s.Chain = append(s.Chain, cert)
+	}
+	if len(s.Chain) == 0 {
+		return nil, fmt.Errorf("%s: no certificate found", certPath)
+	}
+
+	for rest := keyPEM; s.Key == nil; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			return nil, fmt.Errorf("%s: no private key found", keyPath)
+		}
+		var key any
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			return nil, fmt.Errorf("%s: encrypted private keys are not supported", keyPath)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if s.Key, err = signingKey(keyPath, key); err != nil {
+			return nil, err
+		}
+	}
+	if !publicKeysEqual(s.Key.Public(), s.Chain[0].PublicKey) {
+		return nil, errors.New("the private key does not belong to the first certificate")
+	}
+	return s, nil
+}
+
+// loadPKCS12Signer reads the private key, its certificate and the
+// certificates that issued it from the PKCS#12 file at path.
+func loadPKCS12Signer(path, password string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, cert, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s := &Signer{Chain: append([]*x509.Certificate{cert}, chain...)}
+	if s.Key, err = signingKey(path, key); err != nil {
+		return nil, err
+	}
+	if !publicKeysEqual(s.Key.Public(), cert.PublicKey) {
+		return nil, errors.New("the private key does not belong to the first certificate")
+	}
+	return s, nil
+}
+
+// signingKey returns key, read from path, if it is an RSA or ECDSA key.
+func signingKey(path string, key any) (crypto.Signer, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return key.(crypto.Signer), nil
+	}
+	return nil, fmt.Errorf("%s: unsupported key type %T", path, key)
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
+
+// name returns the common name of the signing certificate.
+func (s *Signer) name() string {
+	if cn := s.Chain[0].Subject.CommonName; cn != "" {
+		return cn
+	}
+	return s.Chain[0].Subject.String()
+}
+
+// addSignOffPage ends the report with the verifier's sign-off: a box that
+// shows who signed the report and when, and returns it as the field of the
+// visible signature.
+func addSignOffPage(pdf *gofpdf.Fpdf, info ReportInfo, s *Signer, at time.Time) *SignatureField {
+	pdf.AddPage()
+	generateFlowContent(pdf,
+		Heading{Text: "การลงนามของผู้ทวนสอบ", Align: "C"},
+		Paragraph{Text: info.Verifier + " ได้ทวนสอบ" + info.Title + "ของ" + info.Organisation + " ระยะเวลา " + info.Period + " และลงนามรับรองด้วยลายมือชื่อดิจิทัล", Indent: true},
+		Spacer{Height: 10},
+	)
+
+	w, _ := pdf.GetPageSize()
+	x, y := (w-signatureBoxWidth)/2, pdf.GetY()
+	pdf.Rect(x, y, signatureBoxWidth, signatureBoxHeight, "D")
+	pdf.SetFont("THSarabunNew", "", 14)
+	drawLines(pdf, x, y+3, signatureBoxWidth, 8, []string{
+		"ลงนามแบบดิจิทัลโดย",
+		s.name(),
+		"วันที่ " + thaiDate(at) + " เวลา " + at.Format("15:04:05"),
+	})
+	pdf.SetXY(x, y+signatureBoxHeight+2)
+	pdf.CellFormat(signatureBoxWidth, 8, "ผู้ทวนสอบ "+info.Verifier, "", 1, "C", false, 0, "")
+
+	_, h := pdf.GetPageSize()
+	k := pdf.GetConversionRatio()
+	return &SignatureField{
+		Page: pdf.PageNo(),
+		Rect: [4]float64{x * k, (h - y - signatureBoxHeight) * k, (x + signatureBoxWidth) * k, (h - y) * k},
+	}
+}
+
+// sign appends a signature of data to it as an incremental update, in
+// field when it is visible or in an invisible field on the first page.
+func (s *Signer) sign(data []byte, field *SignatureField, at time.Time) ([]byte, error) {
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(f.trailer, "/Encrypt") {
+		return nil, errors.New("signing encrypted documents is not supported")
+	}
+	root, ok := dictRef(f.trailer, "Root")
+	if !ok {
+		return nil, errors.New("pdf: trailer has no /Root")
+	}
+	catalog, err := f.object(root)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(catalog, "/AcroForm") {
+		return nil, errors.New("signing documents that already have a form is not supported")
+	}
+	pages, err := f.pages(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	if field == nil {
+		field = &SignatureField{Page: 1}
+	}
+	if field.Page < 1 || field.Page > len(pages) {
+		return nil, fmt.Errorf("pdf: no page %d for the signature", field.Page)
+	}
+	page := pages[field.Page-1]
+	pageDict, err := f.object(page)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, widget, appearance := f.size(), f.size()+1, f.size()+2
+	reserve := signatureReserve
+	for _, cert := range s.Chain {
+		reserve += len(cert.Raw)
+	}
+	r := field.Rect
+
+	objects := []pdfObject{
+		{num: root, body: []byte(insertIntoDict(catalog, fmt.Sprintf("/AcroForm << /Fields [%d 0 R] /SigFlags 3 >>", widget)))},
+		{num: page, body: []byte(addAnnotation(pageDict, widget))},
+		{num: sig, body: []byte(fmt.Sprintf("<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached "+
+			"/ByteRange [0 %[1]s %[1]s %[1]s] /Contents <%[2]s> /M %[3]s /Name %[4]s /Reason %[5]s >>",
+			strings.Repeat(" ", 10), strings.Repeat("0", 2*reserve),
+			encodeTextString(pdfDate(at)), encodeTextString(s.name()), encodeTextString(s.Reason)))},
+		{num: widget, body: []byte(fmt.Sprintf("<< /Type /Annot /Subtype /Widget /FT /Sig /T (Signature1) /V %d 0 R /F 132 /P %d 0 R "+
+			"/Rect [%.2f %.2f %.2f %.2f] /AP << /N %d 0 R >> >>", sig, page, r[0], r[1], r[2], r[3], appearance))},
+		{num: appearance, body: streamObject(fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %.2f %.2f] /Resources << >>", r[2]-r[0], r[3]-r[1]), nil)},
+	}
+	out := f.update(objects, "")
+
+	// The signature covers the whole file except its own hex string.
+	contents := bytes.LastIndex(out, []byte("/Contents <")) + len("/Contents ")
+	end := contents + 2*reserve + 2
+	byteRange := fmt.Sprintf("0 %d %d %d", contents, end, len(out)-end)
+	start := bytes.LastIndex(out, []byte("/ByteRange [")) + len("/ByteRange [")
+	placeholder := bytes.IndexByte(out[start:], ']')
+	if len(byteRange) > placeholder {
+		return nil, errors.New("pdf: byte range does not fit")
+	}
+	copy(out[start:], byteRange+strings.Repeat(" ", placeholder-len(byteRange)))
+
+	digest := sha256.New()
+	digest.Write(out[:contents])
+	digest.Write(out[end:])
+	cms, err := s.signedData(digest.Sum(nil), at)
+	if err != nil {
+		return nil, err
+	}
+	if len(cms) > reserve {
+		return nil, errors.New("pdf: signature does not fit the space reserved for it")
+	}
+	copy(out[contents+1:], strings.ToUpper(hex.EncodeToString(cms)))
+	return out, nil
+}
+
+// pages returns the page objects of the document in order.
+func (f *pdfFile) pages(catalog string) ([]int, error) {
+	ref, ok := dictRef(catalog, "Pages")
+	if !ok {
+		return nil, errors.New("pdf: catalog has no /Pages")
+	}
+	var pages []int
+	var walk func(ref, depth int) error
+	walk = func(ref, depth int) error {
+		dict, err := f.object(ref)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(dict, "/Kids") {
+			pages = append(pages, ref)
+			return nil
+		}
+		if depth > 32 {
+			return errors.New("pdf: page tree too deep")
+		}
+		kids := regexp.MustCompile(`/Kids\s*\[([^\]]*)\]`).FindStringSubmatch(dict)
+		if kids == nil {
+			return fmt.Errorf("pdf: bad /Kids in object %d", ref)
+		}
+		for _, m := range regexp.MustCompile(`(\d+)\s+0\s+R`).FindAllStringSubmatch(kids[1], -1) {
+			kid, _ := strconv.Atoi(m[1])
+			if err := walk(kid, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return pages, walk(ref, 0)
+}
+
+// insertIntoDict adds entries at the end of the dictionary dict.
+func insertIntoDict(dict, entries string) string {
+	dict = strings.TrimSpace(dict)
+	return strings.TrimSuffix(dict, ">>") + " " + entries + " >>"
+}
+
+// addAnnotation adds the annotation annot to the /Annots of a page.
+func addAnnotation(page string, annot int) string {
+	annots := regexp.MustCompile(`/Annots\s*\[`)
+	if loc := annots.FindStringIndex(page); loc != nil {
+		return page[:loc[1]] + fmt.Sprintf("%d 0 R ", annot) + page[loc[1]:]
+	}
+	return insertIntoDict(page, fmt.Sprintf("/Annots [%d 0 R]", annot))
+}
+
+// der returns the DER encoding of a value with the given class and tag
+// whose content is the concatenation of content.
+func der(class, tag int, compound bool, content ...[]byte) []byte {
+	b, _ := asn1.Marshal(asn1.RawValue{Class: class, Tag: tag, IsCompound: compound, Bytes: bytes.Join(content, nil)})
+	return b
+}
+
+func derSequence(content ...[]byte) []byte {
+	return der(asn1.ClassUniversal, asn1.TagSequence, true, content...)
+}
+
+// derSet encodes a SET OF, whose elements DER sorts by their encoding.
+func derSet(content ...[]byte) []byte {
+	return der(asn1.ClassUniversal, asn1.TagSet, true, sortDER(content)...)
+}
+
+func sortDER(content [][]byte) [][]byte {
+	sorted := append([][]byte(nil), content...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	return sorted
+}
+
+// derEncoder marshals ASN.1 values and keeps the first error, so that a
+// structure is built in one expression and checked once.
+type derEncoder struct {
+	err error
+}
+
+func (e *derEncoder) marshal(v any) []byte {
+	b, err := asn1.Marshal(v)
+	if err != nil && e.err == nil {
+		e.err = fmt.Errorf("encoding the signature: %w", err)
+	}
+	return b
+}
+
+// signedData returns a CMS SignedData without content that signs digest,
+// the SHA-256 of the signed bytes, at the time at.
+func (s *Signer) signedData(digest []byte, at time.Time) ([]byte, error) {
+	var enc derEncoder
+	var signatureAlgorithm []byte
+	switch s.Key.(type) {
+	case *rsa.PrivateKey:
+		signatureAlgorithm = derSequence(enc.marshal(oidRSAEncryption), enc.marshal(asn1.NullRawValue))
+	case *ecdsa.PrivateKey:
+		signatureAlgorithm = derSequence(enc.marshal(oidECDSAWithSHA256))
+	}
+	digestAlgorithm := derSequence(enc.marshal(oidSHA256))
+
+	attributes := sortDER([][]byte{
+		derSequence(enc.marshal(oidContentType), derSet(enc.marshal(oidData))),
+		derSequence(enc.marshal(oidSigningTime), derSet(enc.marshal(at.UTC()))),
+		derSequence(enc.marshal(oidMessageDigest), derSet(enc.marshal(digest))),
+	})
+	// The signature is over the attributes encoded as a SET, although they
+	// are stored with the context tag [0].
+	signed := der(asn1.ClassUniversal, asn1.TagSet, true, attributes...)
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	hash := sha256.Sum256(signed)
+	signature, err := s.Key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := s.Chain[0]
+	signerInfo := derSequence(
+		enc.marshal(1),
+		derSequence(cert.RawIssuer, enc.marshal(cert.SerialNumber)),
+		digestAlgorithm,
+		der(asn1.ClassContextSpecific, 0, true, attributes...),
+		signatureAlgorithm,
+		enc.marshal(signature),
+	)
+
+	var certs [][]byte
+	for _, c := range s.Chain {
+		certs = append(certs, c.Raw)
+	}
+	signedData := derSequence(
+		enc.marshal(1),
+		derSet(digestAlgorithm),
+		derSequence(enc.marshal(oidData)),
+		der(asn1.ClassContextSpecific, 0, true, certs...),
+		derSet(signerInfo),
+	)
+	cms := derSequence(enc.marshal(oidSignedData), der(asn1.ClassContextSpecific, 0, true, signedData))
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	return cms, nil
+}
+
+// SignatureStatus is the outcome of checking one signature of a PDF.
+type SignatureStatus struct {
+	Field  string
+	Signer string
+	// Time is the signing time the signer claims, or zero.
+	Time time.Time
+	// Covered is how much of the file the signature covers: all of it
+	// unless the file was updated after signing.
+	Covered, Size int
+	// Err is why the signature does not match the file, or nil.
+	Err error
+	// Trust is why the signing certificate is not trusted, or nil.
+	Trust error
+}
+
+// Valid reports whether the signature matches, covers the whole file and
+// comes from a trusted certificate.
+func (s SignatureStatus) Valid() bool {
+	return s.Err == nil && s.Trust == nil && s.Covered == s.Size
+}
+
+var (
+	byteRangePattern = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+	digestAlgorithms = map[string]crypto.Hash{
+		"1.3.14.3.2.26":          crypto.SHA1,
+		oidSHA256.String():       crypto.SHA256,
+		"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+		"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+	}
+)
+
+// verifySignatures checks every signature of data, trusting the
+// certificates in roots, or the system's when roots is nil.
+func verifySignatures(data []byte, roots *x509.CertPool) ([]SignatureStatus, error) {
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []SignatureStatus
+	for _, num := range f.objectNumbers() {
+		dict, err := f.object(num)
+		if err != nil || !strings.Contains(dict, "/ByteRange") {
+			continue
+		}
+		status := SignatureStatus{Field: "object " + strconv.Itoa(num), Size: len(data)}
+		// The field is the widget whose value is this signature.
+		for _, other := range f.objectNumbers() {
+			widget, err := f.object(other)
+			if err == nil && regexp.MustCompile(`/V\s+`+strconv.Itoa(num)+`\s+0\s+R`).MatchString(widget) {
+				if name, ok := dictString(widget, "T"); ok {
+					status.Field = name
+				}
+			}
+		}
+		status.Err = verifySignature(data, dict, roots, &status)
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// verifySignature checks the signature dictionary dict against data and
+// fills in status.
+func verifySignature(data []byte, dict string, roots *x509.CertPool, status *SignatureStatus) error {
+	m := byteRangePattern.FindStringSubmatch(dict)
+	if m == nil {
+		return errors.New("no byte range")
+	}
+	var r [4]int
+	for i := range r {
+		r[i], _ = strconv.Atoi(m[i+1])
+	}
+	if r[0] != 0 || r[1] > r[2] || r[2]+r[3] > len(data) {
+		return errors.New("the byte range is outside the file")
+	}
+	status.Covered = r[2] + r[3]
+	gap := data[r[1]:r[2]]
+	if len(gap) < 2 || gap[0] != '<' || gap[len(gap)-1] != '>' {
+		return errors.New("the byte range does not exclude exactly the signature")
+	}
+	raw, err := hex.DecodeString(string(gap[1 : len(gap)-1]))
+	if err != nil {
+		return fmt.Errorf("bad signature contents: %v", err)
+	}
+
+	certs, info, err := parseSignedData(raw)
+	if err != nil {
+		return err
+	}
+
+	// SignerInfo: version, sid, digestAlgorithm, [0] signedAttrs,
+	// signatureAlgorithm, signature.
+	if len(info) < 5 {
+		return errors.New("bad signer info")
+	}
+	sid, err := derElements(info[1].Bytes)
+	if err != nil || len(sid) != 2 {
+		return errors.New("unsupported signer identifier")
+	}
+	var serial *big.Int
+	if _, err := asn1.Unmarshal(sid[1].FullBytes, &serial); err != nil {
+		return err
+	}
+	var cert *x509.Certificate
+	for _, c := range certs {
+		if bytes.Equal(c.RawIssuer, sid[0].FullBytes) && c.SerialNumber.Cmp(serial) == 0 {
+			cert = c
+		}
+	}
+	if cert == nil {
+		return errors.New("the signing certificate is missing")
+	}
+	status.Signer = cert.Subject.CommonName
+	if status.Signer == "" {
+		status.Signer = cert.Subject.String()
+	}
+
+	algorithm, err := derElements(info[2].Bytes)
+	if err != nil || len(algorithm) == 0 {
+		return errors.New("bad digest algorithm")
+	}
+	var oid asn1.ObjectIdentifier
+	asn1.Unmarshal(algorithm[0].FullBytes, &oid)
+	hash, ok := digestAlgorithms[oid.String()]
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm %s", oid)
+	}
+	h := hash.New()
+	h.Write(data[:r[1]])
+	h.Write(data[r[2] : r[2]+r[3]])
+	digest := h.Sum(nil)
+
+	rest := info[3:]
+	signed := digest
+	if rest[0].Class == asn1.ClassContextSpecific && rest[0].Tag == 0 {
+		attributes, err := derElements(rest[0].Bytes)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, attribute := range attributes {
+			parts, err := derElements(attribute.Bytes)
+			if err != nil || len(parts) != 2 {
+				return errors.New("bad signed attribute")
+			}
+			var attributeType asn1.ObjectIdentifier
+			asn1.Unmarshal(parts[0].FullBytes, &attributeType)
+			values, err := derElements(parts[1].Bytes)
+			if err != nil || len(values) == 0 {
+				return errors.New("bad signed attribute")
+			}
+			switch {
+			case attributeType.Equal(oidMessageDigest):
+				found = true
+				if !bytes.Equal(values[0].Bytes, digest) {
+					return errors.New("the document was changed after it was signed")
+				}
+			case attributeType.Equal(oidSigningTime):
+				asn1.Unmarshal(values[0].FullBytes, &status.Time)
+			}
+		}
+		if !found {
+			return errors.New("the signed attributes have no message digest")
+		}
+		// The attributes are signed as a SET rather than with their tag.
+		set := append([]byte(nil), rest[0].FullBytes...)
+		set[0] = 0x31
+		h := hash.New()
+		h.Write(set)
+		signed = h.Sum(nil)
+		rest = rest[1:]
+	}
+	if len(rest) < 2 {
+		return errors.New("bad signer info")
+	}
+	if err := checkSignature(cert, hash, signed, rest[1].Bytes); err != nil {
+		return fmt.Errorf("the signature does not match: %v", err)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		intermediates.AddCert(c)
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   status.Time,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	_, status.Trust = cert.Verify(opts)
+	return nil
+}
+
+// parseSignedData returns the certificates and the first signer info of a
+// CMS SignedData.
+func parseSignedData(raw []byte) ([]*x509.Certificate, []asn1.RawValue, error) {
+	var contentInfo asn1.RawValue
+	if _, err := asn1.Unmarshal(raw, &contentInfo); err != nil {
+		return nil, nil, fmt.Errorf("bad signature: %v", err)
+	}
+	parts, err := derElements(contentInfo.Bytes)
+	if err != nil || len(parts) != 2 {
+		return nil, nil, errors.New("bad signature: not a content info")
+	}
+	var contentType asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(parts[0].FullBytes, &contentType); err != nil || !contentType.Equal(oidSignedData) {
+		return nil, nil, errors.New("bad signature: not signed data")
+	}
+	outer, err := derElements(parts[1].Bytes)
+	if err != nil || len(outer) != 1 {
+		return nil, nil, errors.New("bad signature: bad signed data")
+	}
+	signedData, err := derElements(outer[0].Bytes)
+	if err != nil || len(signedData) < 4 {
+		return nil, nil, errors.New("bad signature: bad signed data")
+	}
+
+	var certs []*x509.Certificate
+	var infos []asn1.RawValue
+	for _, e := range signedData[3:] {
+		switch {
+		case e.Class == asn1.ClassContextSpecific && e.Tag == 0:
+			if certs, err = x509.ParseCertificates(e.Bytes); err != nil {
+				return nil, nil, err
+			}
+		case e.Class == asn1.ClassUniversal && e.Tag == asn1.TagSet:
+			if infos, err = derElements(e.Bytes); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if len(infos) == 0 {
+		return nil, nil, errors.New("bad signature: no signer")
+	}
+	info, err := derElements(infos[0].Bytes)
+	return certs, info, err
+}
+
+// derElements returns the DER values that make up b.
+func derElements(b []byte) ([]asn1.RawValue, error) {
+	var elements []asn1.RawValue
+	for len(b) > 0 {
+		var v asn1.RawValue
+		rest, err := asn1.Unmarshal(b, &v)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, v)
+		b = rest
+	}
+	return elements, nil
+}
+
+// checkSignature checks signature over the hash digest with the public
+// key of cert.
+func checkSignature(cert *x509.Certificate, hash crypto.Hash, digest, signature []byte) error {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, signature) {
+			return errors.New("ECDSA verification failed")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported public key %T", cert.PublicKey)
+}
+
+// runVerify implements the verify command: it checks the signatures of
+// the PDF files named in args and returns the exit status, 1 when any of
+// them is not valid.
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	rootsPath := flags.String("roots", "", "trust the CA certificates in the PEM `file` instead of the system's")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Println("usage: go-pdf verify [-roots file] file.pdf ...")
+		return 2
+	}
+
+	var roots *x509.CertPool
+	if *rootsPath != "" {
+		pemData, err := os.ReadFile(*rootsPath)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return 1
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pemData) {
+			fmt.Println("Error: no certificates in", *rootsPath)
+			return 1
+		}
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var statuses []SignatureStatus
+			if statuses, err = verifySignatures(data, roots); err == nil && len(statuses) == 0 {
+				err = errors.New("no signatures")
+			}
+			for _, s := range statuses {
+				printSignatureStatus(path, s)
+				if !s.Valid() {
+					status = 1
+				}
+			}
+		}
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+func printSignatureStatus(path string, s SignatureStatus) {
+	if s.Err != nil {
+		fmt.Printf("%s: %s: invalid: %v\n", path, s.Field, s.Err)
+		return
+	}
+	fmt.Printf("%s: %s: signature matches, signed by %s", path, s.Field, s.Signer)
+	if !s.Time.IsZero() {
+		fmt.Printf(" at %s", s.Time.Local().Format("2006-01-02 15:04:05 -0700"))
+	}
+	fmt.Println()
+	if s.Covered != s.Size {
+		fmt.Printf("  the file was changed after signing: the signature covers %d of %d bytes\n", s.Covered, s.Size)
+	}
+	if s.Trust != nil {
+		fmt.Printf("  certificate not trusted: %v\n", s.Trust)
+	}
+}
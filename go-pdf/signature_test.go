@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// testSigner returns a signer with a self-signed certificate for key.
+func testSigner(t *testing.T, key crypto.Signer) *Signer {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Verifier"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Signer{Key: key, Chain: []*x509.Certificate{cert}, Reason: "ทวนสอบ"}
+}
+
+// testPDF returns a two page PDF, the second page with a visible
+// signature field when visible is set.
+func testPDF(t *testing.T, s *Signer, visible bool) ([]byte, *SignatureField) {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8Font("THSarabunNew", "", "./THSarabunNew.ttf")
+	pdf.AddUTF8Font("THSarabunNew", "B", "./THSarabunNew Bold.ttf")
+	pdf.AddPage()
+	pdf.SetFont("THSarabunNew", "", 14)
+	pdf.Cell(40, 10, "รายงาน")
+	var field *SignatureField
+	if visible {
+		field = addSignOffPage(pdf, reportInfo, s, time.Now())
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), field
+}
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		key     crypto.Signer
+		visible bool
+	}{
+		{"RSA", rsaKey, false},
+		{"ECDSA", ecKey, false},
+		{"RSA visible", rsaKey, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testSigner(t, tt.key)
+			data, field := testPDF(t, s, tt.visible)
+			signed, err := s.sign(data, field, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			roots := x509.NewCertPool()
+			roots.AddCert(s.Chain[0])
+
+			statuses, err := verifySignatures(signed, roots)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(statuses) != 1 {
+				t.Fatalf("got %d signatures, want 1", len(statuses))
+			}
+			if status := statuses[0]; !status.Valid() {
+				t.Errorf("signature not valid: err %v, trust %v, covered %d of %d", status.Err, status.Trust, status.Covered, status.Size)
+			}
+			if statuses[0].Signer != "Test Verifier" {
+				t.Errorf("signer %q, want %q", statuses[0].Signer, "Test Verifier")
+			}
+
+			// A change to the signed bytes breaks the signature.
+			changed := bytes.Replace(signed, []byte("/Producer"), []byte("/Producex"), 1)
+			if bytes.Equal(changed, signed) {
+				t.Fatal("no /Producer to change")
+			}
+			if statuses, err := verifySignatures(changed, roots); err != nil || len(statuses) != 1 || statuses[0].Err == nil {
+				t.Errorf("changed document verified: %v, %v", statuses, err)
+			}
+
+			// Bytes added after signing are not covered.
+			appended := append(append([]byte(nil), signed...), "\n% added\n"...)
+			if statuses, err := verifySignatures(appended, roots); err != nil || len(statuses) != 1 || statuses[0].Valid() {
+				t.Errorf("updated document verified as whole: %v, %v", statuses, err)
+			}
+
+			// Without the certificate among the roots it is not trusted.
+			if statuses, err := verifySignatures(signed, x509.NewCertPool()); err != nil || len(statuses) != 1 || statuses[0].Trust == nil {
+				t.Errorf("untrusted certificate accepted: %v, %v", statuses, err)
+			}
+		})
+	}
+}
+
+func TestLoadPKCS12Signer(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testSigner(t, key)
+	data, err := pkcs12.Modern.Encode(key, s.Chain[0], nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "signer.p12")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadSigner(path, "", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Chain[0].Equal(s.Chain[0]) {
+		t.Error("loaded a different certificate")
+	}
+	if _, err := loadSigner(path, "", "wrong"); err == nil {
+		t.Error("loaded with a wrong password")
+	}
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity tells whether a finding makes the report invalid.
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Finding is a problem found in the report model, with the path of the
+// field it concerns, e.g. "scope2Summary.total".
+type Finding struct {
+	Severity Severity
+	Path     string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return f.Severity.String() + ": " + f.Path + ": " + f.Message
+}
+
+// hasErrors reports whether any of findings is an error.
+func hasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeSections are the sections of 3.2 that list the sources of each
+// scope; sections 4 and 5 follow the scope number.
+var scopeSections = map[int]string{1: "3.2.1", 2: "3.2.4", 3: "3.2.6"}
+
+var sourceNumber = regexp.MustCompile(`^\d+\.\s*`)
+
+// sourceKey returns name without its list number, spacing and case, so that
+// the same source matches across sections.
+func sourceKey(name string) string {
+	name = sourceNumber.ReplaceAllString(strings.TrimSpace(name), "")
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// validator collects the findings of validate.
+type validator struct {
+	findings []Finding
+}
+
+func (v *validator) errorf(path, format string, args ...any) {
+	v.findings = append(v.findings, Finding{Error, path, fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(path, format string, args ...any) {
+	v.findings = append(v.findings, Finding{Warning, path, fmt.Sprintf(format, args...)})
+}
+
+// required reports an error for each of fields, given as path and value
+// pairs, that is blank.
+func (v *validator) required(fields ...string) {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if strings.TrimSpace(fields[i+1]) == "" {
+			v.errorf(fields[i], "required")
+		}
+	}
+}
+
+// validate checks the report for missing fields, duplicated activities,
+// sources that lack monitoring data or emissions, and totals that do not
+// match their rows.
+func (r Report) validate() []Finding {
+	v := &validator{}
+	r.validateInfo(v)
+	r.validateFacilities(v)
+	r.validateFuels(v)
+	r.validateEnergy(v)
+	r.validateActivities(v)
+	r.validateSources(v)
+	r.validateScope3(v)
+	r.validateCredits(v)
+	r.validateMonitoring(v)
+	r.validateSummaries(v)
+	r.validateDenominators(v)
+	r.validateBaseYear(v)
+	return v.findings
+}
+
+func (r Report) validateInfo(v *validator) {
+	if r.Materiality <= 0 || r.Materiality >= 1 {
+		v.errorf("materiality", "%v is not a fraction between 0 and 1", r.Materiality)
+	}
+	info := r.Info
+	v.required(
+		"info.title", info.Title,
+		"info.organisation", info.Organisation,
+		"info.address", info.Address,
+		"info.preparer", info.Preparer,
+		"info.verifier", info.Verifier,
+		"info.period", info.Period,
+		"info.formCode", info.FormCode,
+		"info.formVersion", info.FormVersion,
+	)
+	if info.Date.IsZero() {
+		v.errorf("info.date", "required")
+	}
+}
+
+func (r Report) validateFacilities(v *validator) {
+	if r.Consolidation < OperationalControl || r.Consolidation > EquityShare {
+		v.errorf("consolidation", "%d is not a consolidation approach", r.Consolidation)
+	}
+	if len(r.Facilities) == 0 {
+		v.errorf("facilities", "required")
+	}
+	seen := map[string]int{}
+	for i, f := range r.Facilities {
+		path := fmt.Sprintf("facilities[%d]", i)
+		v.required(path+".code", f.Code, path+".name", f.Name)
+		if first, ok := seen[f.Code]; ok {
+			v.errorf(path+".code", "%q is also used by facilities[%d]", f.Code, first)
+		}
+		seen[f.Code] = i
+		if f.EquityShare < 0 || f.EquityShare > 1 {
+			v.errorf(path+".equityShare", "%v is not a fraction between 0 and 1", f.EquityShare)
+		}
+		if f.share(r.Consolidation) == 0 {
+			v.warnf(path, "%s is not counted under %s", f.Code, r.Consolidation)
+		}
+	}
+
+	for i, record := range r.Records {
+		path := fmt.Sprintf("records[%d]", i)
+		v.required(path+".source", record.Source)
+		if record.Scope < 1 || record.Scope > 3 {
+			v.errorf(path+".scope", "scope %d is not 1, 2 or 3", record.Scope)
+		}
+		if record.Emissions < 0 {
+			v.errorf(path+".emissions", "%v is negative", record.Emissions)
+		}
+		if record.Scope == 2 {
+			v.warnf(path, "the energy bought for %s is not known, so section 5.2 shows it as pending; record it in energy", record.Source)
+		}
+		r.validateRecordFacility(v, path, record.Facility)
+	}
+}
+
+// validateRecordFacility checks that the data recorded at path for facility
+// code can be consolidated.
+func (r Report) validateRecordFacility(v *validator, path, code string) {
+	if _, ok := r.consolidationShare(code); !ok {
+		if code == "" {
+			v.errorf(path+".facility", "data recorded for all facilities together cannot be consolidated under %s as their shares differ", r.Consolidation)
+		} else {
+			v.errorf(path+".facility", "%q is not a facility", code)
+		}
+	}
+}
+
+func (r Report) validateFuels(v *validator) {
+	seen := map[string]int{}
+	for i, f := range r.Fuels {
+		path := fmt.Sprintf("fuels[%d]", i)
+		v.required(path+".name", f.Name)
+		if first, ok := seen[f.Name]; ok {
+			v.errorf(path+".name", "%q is also used by fuels[%d]", f.Name, first)
+		}
+		seen[f.Name] = i
+		if f.Origin != Fossil && f.Origin != Biogenic {
+			v.errorf(path+".origin", "%d is not fossil or biogenic", f.Origin)
+		}
+	}
+
+	for i, record := range r.FuelRecords {
+		path := fmt.Sprintf("fuelRecords[%d]", i)
+		v.required(path+".category", record.Category, path+".source", record.Source, path+".fuel", record.Fuel)
+		f, ok := r.fuel(record.Fuel)
+		if !ok && record.Fuel != "" {
+			v.errorf(path+".fuel", "%q is not listed in fuels", record.Fuel)
+		}
+		for g, amount := range record.Gases {
+			if g < CO2 || g > PFCs {
+				v.errorf(path+".gases", "%s is not a gas of section 5.1", g)
+			}
+			if amount < 0 {
+				v.errorf(path+".gases", "%s %v is negative", g, amount)
+			}
+		}
+		switch {
+		case ok && f.Origin == Biogenic && record.Gases[FossilCH4] != 0:
+			v.errorf(path+".gases", "%s is biogenic, so its CH4 is not fossil", f.Name)
+		case ok && f.Origin == Fossil && record.Gases[CH4] != 0:
+			v.errorf(path+".gases", "%s is fossil, so its CH4 goes under Fossil CH4", f.Name)
+		}
+		r.validateRecordFacility(v, path, record.Facility)
+	}
+}
+
+func (r Report) validateActivities(v *validator) {
+	for i, facility := range r.Activities {
+		path := fmt.Sprintf("activities[%d]", i)
+		v.required(path+".facility", facility.Facility)
+		if _, ok := r.facility(facility.Facility); !ok && facility.Facility != "" {
+			v.errorf(path+".facility", "%q is not a facility", facility.Facility)
+		}
+		for s, activities := range facility.Activities {
+			if len(activities) == 0 {
+				v.warnf(path, "no scope %d activities in section 3.1.4", s+1)
+			}
+			seen := map[string]int{}
+			for j, activity := range activities {
+				apath := fmt.Sprintf("%s.scope%d[%d]", path, s+1, j)
+				key := sourceKey(activity)
+				if key == "" {
+					v.errorf(apath, "required")
+					continue
+				}
+				if first, ok := seen[key]; ok {
+					v.errorf(apath, "%q is listed twice in section 3.1.4, see scope%d[%d]", strings.TrimSpace(activity), s+1, first)
+					continue
+				}
+				seen[key] = j
+			}
+		}
+	}
+}
+
+func (r Report) validateSources(v *validator) {
+	seen := map[string]string{}
+	for i, source := range r.Sources {
+		r.validateSource(v, fmt.Sprintf("sources[%d]", i), source, seen)
+	}
+	for i, entry := range r.Scope3 {
+		if entry.Included {
+			r.validateSource(v, fmt.Sprintf("scope3[%d]", i), entry.source(), seen)
+		}
+	}
+}
+
+// validateSource checks a source listed in section 3.2. seen maps the
+// sources already checked to their path.
+func (r Report) validateSource(v *validator, path string, source ScopeSource, seen map[string]string) {
+	section := scopeSections[source.Scope]
+	if section == "" {
+		v.errorf(path+".scope", "scope %d is not 1, 2 or 3", source.Scope)
+		return
+	}
+	v.required(
+		path+".facility", source.Facility,
+		path+".name", source.Name,
+		path+".location", source.Location,
+	)
+	if strings.TrimSpace(source.Internal) == "" && strings.TrimSpace(source.External) == "" {
+		v.errorf(path, "neither ใช้ภายใน nor จำหน่ายภายนอก is marked in section %s", section)
+	}
+	computed, ok := r.computedSignificance(source)
+	switch {
+	case source.Override != "" && source.Override != significant && source.Override != notSignificant:
+		v.errorf(path+".override", "%q is not %s or %s", source.Override, significant, notSignificant)
+	case source.Override != "" && strings.TrimSpace(source.Justification) == "":
+		v.errorf(path+".justification", "required to override the significance")
+	case source.Override != "" && source.Override == computed:
+		v.warnf(path+".override", "%q is what the %s%% threshold gives", source.Override, r.materialityPercent())
+	case source.Override == "" && source.Justification != "":
+		v.warnf(path+".justification", "given without an override")
+	}
+	if !ok && source.Override == "" {
+		v.errorf(path, "the significance of %q cannot be computed without its emissions in the inventory", strings.TrimSpace(source.Name))
+	}
+
+	key := strconv.Itoa(source.Scope) + "/" + sourceKey(source.Name)
+	if first, ok := seen[key]; ok {
+		v.warnf(path, "%q is also listed at %s", strings.TrimSpace(source.Name), first)
+		return
+	}
+	seen[key] = path
+	if !r.hasMonitoring(source.Scope, source.Name) {
+		v.errorf(path, "%q has no monitoring data in section 4.%d", strings.TrimSpace(source.Name), source.Scope)
+	}
+	if !r.hasSummary(source.Scope, source.Name) {
+		v.errorf(path, "%q has no emissions in section 5.%d", strings.TrimSpace(source.Name), source.Scope)
+	}
+}
+
+func (r Report) validateScope3(v *validator) {
+	listed := map[Scope3Category]int{}
+	for i, entry := range r.Scope3 {
+		path := fmt.Sprintf("scope3[%d]", i)
+		if entry.Category < PurchasedGoods || entry.Category > Investments {
+			v.errorf(path+".category", "%d is not a Scope 3 category", entry.Category)
+			continue
+		}
+		if first, ok := listed[entry.Category]; ok {
+			v.errorf(path+".category", "%q is also listed at scope3[%d]", entry.Category, first)
+			continue
+		}
+		listed[entry.Category] = i
+		if !entry.Included && strings.TrimSpace(entry.Reason) == "" {
+			v.errorf(path+".reason", "required to exclude %q", entry.Category)
+		}
+	}
+	for c := PurchasedGoods; c <= Investments; c++ {
+		if _, ok := listed[c]; !ok {
+			v.warnf("scope3", "%q is neither included nor excluded", c)
+		}
+	}
+}
+
+func (r Report) validateMonitoring(v *validator) {
+	for i, m := range r.Monitoring {
+		path := fmt.Sprintf("monitoring[%d]", i)
+		v.required(path+".source", m.Source, path+".efSource", m.EFSource)
+		for _, list := range []struct {
+			name   string
+			values []string
+		}{{"units", m.Units}, {"points", m.Points}, {"evidence", m.Evidence}} {
+			if len(list.values) == 0 {
+				v.errorf(path+"."+list.name, "required")
+			}
+			for j, value := range list.values {
+				v.required(fmt.Sprintf("%s.%s[%d]", path, list.name, j), value)
+			}
+		}
+		switch m.Origin {
+		case OriginMeasured, OriginPayment, OriginEstimated:
+		case "":
+			v.errorf(path+".origin", "where the activity data comes from is not marked in section 4.%d", m.Scope)
+		default:
+			v.errorf(path+".origin", "%q is not %s, %s or %s", m.Origin, OriginMeasured, OriginPayment, OriginEstimated)
+		}
+		if !r.hasSource(m.Scope, m.Source) {
+			v.warnf(path, "%q is not listed in section %s", strings.TrimSpace(m.Source), scopeSections[m.Scope])
+		}
+	}
+}
+
+// summaryTolerance is the difference in tCO2e below which two totals of
+// section 5 are the same.
+const summaryTolerance = 1e-6
+
+func (r Report) validateSummaries(v *validator) {
+	for _, table := range r.summaryTables() {
+		path := fmt.Sprintf("scope%dSummary", table.Scope)
+		sum := 0.0
+		for j, row := range table.Rows {
+			rpath := fmt.Sprintf("%s.rows[%d]", path, j)
+			v.required(rpath+".source", row.Source)
+			if row.Missing {
+				v.errorf(rpath+".emissions", "%q has no emissions in the inventory", strings.TrimSpace(row.Source))
+				continue
+			}
+			sum += row.Emissions
+			if !r.hasSource(table.Scope, row.Source) {
+				v.warnf(rpath, "%q is not listed in section %s", strings.TrimSpace(row.Source), scopeSections[table.Scope])
+			}
+		}
+
+		// The values are compared before they are rounded for the tables,
+		// so only floating point error is allowed for.
+		if math.Abs(table.Total-sum) > summaryTolerance {
+			v.errorf(path+".total", "section 5.%d total %s does not match its rows, which sum to %s", table.Scope, formatNumber(table.Total, 2), formatNumber(sum, 2))
+		}
+		if inventory := r.inventory().ScopeTotal(table.Scope); math.Abs(inventory-sum) > summaryTolerance {
+			v.errorf(path, "section 5.%d rows sum to %s but the inventory has %s", table.Scope, formatNumber(sum, 2), formatNumber(inventory, 2))
+		}
+	}
+}
+
+func (r Report) validateCredits(v *validator) {
+	for i, c := range r.Credits {
+		path := fmt.Sprintf("credits[%d]", i)
+		v.required(path+".name", c.Name, path+".standard", c.Standard, path+".period", c.Period)
+		if c.Kind < ReductionProject || c.Kind > RenewableCertificate {
+			v.errorf(path+".kind", "%d is not a kind of credit", c.Kind)
+		}
+		switch {
+		case c.Sold < 0 || c.Retired < 0:
+			v.errorf(path, "quantities cannot be negative")
+		case c.Sold == 0 && c.Retired == 0:
+			v.warnf(path, "%s has no credits sold or retired", c.Name)
+		}
+	}
+	grid := 0.0
+	for _, p := range r.Energy {
+		if p.Kind == Electricity && p.SupplierFactor == 0 {
+			share, _ := r.consolidationShare(p.Facility)
+			grid += share * p.Quantity
+		}
+	}
+	if kWh := r.retiredCertificates(); kWh > grid {
+		v.warnf("credits", "the retired RECs cover %s kWh but only %s kWh of electricity has no supplier factor; the rest is not deducted", formatNumber(kWh, 0), formatNumber(grid, 0))
+	}
+}
+
+func (r Report) validateEnergy(v *validator) {
+	electricity := false
+	for i, p := range r.Energy {
+		path := fmt.Sprintf("energy[%d]", i)
+		v.required(path+".source", p.Source)
+		if p.Kind < Electricity || p.Kind > Heat {
+			v.errorf(path+".kind", "%d is not electricity, steam or heat", p.Kind)
+		}
+		switch {
+		case p.Quantity < 0 || p.SupplierFactor < 0:
+			v.errorf(path, "values cannot be negative")
+		case p.Quantity == 0:
+			v.warnf(path+".quantity", "the %s bought for %s is not known", p.Kind, p.Source)
+		}
+		if p.Kind == Electricity {
+			electricity = true
+		} else if p.SupplierFactor == 0 {
+			v.errorf(path+".supplierFactor", "required for %s, which has no grid factor", p.Kind)
+		}
+		r.validateRecordFacility(v, path, p.Facility)
+	}
+	if electricity && r.GridFactor <= 0 {
+		v.errorf("gridFactor", "required for the location-based electricity of section 5.2")
+	}
+	if r.GridFactor < 0 || r.ResidualFactor < 0 {
+		v.errorf("gridFactor", "emission factors cannot be negative")
+	}
+}
+
+func (r Report) validateDenominators(v *validator) {
+	for i, d := range r.Denominators {
+		path := fmt.Sprintf("denominators[%d]", i)
+		v.required(path+".name", d.Name, path+".unit", d.Unit)
+		if _, ok := numeratorFactors[d.Numerator]; !ok {
+			v.errorf(path+".numerator", "%q is not kgCO2e or tCO2e", d.Numerator)
+		}
+		switch {
+		case d.Value < 0 || d.BaseValue < 0:
+			v.errorf(path, "values cannot be negative")
+		case d.Value == 0:
+			v.warnf(path+".value", "%s is not known, so section 5.5 shows its intensity as pending", d.Name)
+		case d.BaseValue == 0:
+			v.warnf(path+".baseValue", "%s of the base year is not known, so section 5.5 shows it as pending", d.Name)
+		}
+	}
+}
+
+func (r Report) validateBaseYear(v *validator) {
+	b := r.BaseYear
+	v.required("baseYear.period", b.Period)
+	if b.Original.Year == 0 || b.Original.Year >= r.Year {
+		v.errorf("baseYear.original.year", "%d is not a year before %d", b.Original.Year, r.Year)
+	}
+	if len(b.Original.Sources) == 0 {
+		v.errorf("baseYear.original.sources", "required")
+	}
+	if b.Threshold <= 0 || b.Threshold >= 1 {
+		v.errorf("baseYear.threshold", "%v is not a fraction between 0 and 1", b.Threshold)
+	}
+	for i, c := range b.Changes {
+		path := fmt.Sprintf("baseYear.changes[%d]", i)
+		v.required(path+".source", c.Source, path+".reason", c.Reason)
+		if c.Kind < Acquisition || c.Kind > ErrorCorrection {
+			v.errorf(path+".kind", "%d is not a kind of change", c.Kind)
+		}
+		if c.Scope < 1 || c.Scope > 3 {
+			v.errorf(path+".scope", "scope %d is not 1, 2 or 3", c.Scope)
+		}
+		if c.Delta == 0 {
+			v.warnf(path+".delta", "the change to %s has no effect", c.Source)
+		}
+	}
+
+	if strings.TrimSpace(b.Coverage) != "" {
+		return
+	}
+	current, base := r.inventory(), b.restated()
+	for scope := 1; scope <= 3; scope++ {
+		if current.hasScope(scope) && !base.hasScope(scope) {
+			v.warnf("baseYear", "%d covers %s but the base year does not; record its base year emissions as a change to compare the years, or give the reason in coverage", r.Year, scopeName(scope))
+		}
+	}
+}
+
+// hasSource reports whether a source named name is listed in section 3.2
+// for scope.
+func (r Report) hasSource(scope int, name string) bool {
+	for _, source := range r.sources(scope) {
+		if sourceKey(source.Name) == sourceKey(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMonitoring reports whether section 4 has monitoring data for the
+// source of scope named name.
+func (r Report) hasMonitoring(scope int, name string) bool {
+	for _, m := range r.Monitoring {
+		if m.Scope == scope && sourceKey(m.Source) == sourceKey(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSummary reports whether section 5 has emissions for the source of
+// scope named name.
+func (r Report) hasSummary(scope int, name string) bool {
+	for _, table := range r.summaryTables() {
+		for _, row := range table.Rows {
+			if table.Scope == scope && sourceKey(row.Source) == sourceKey(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runValidate prints the findings of the report and returns the exit code:
+// 1 when the report has errors.
+func runValidate(args []string) int {
+	if len(args) > 0 {
+		fmt.Println("usage: go-pdf validate")
+		return 2
+	}
+	findings := reportData.validate()
+	printFindings(findings)
+	if hasErrors(findings) {
+		return 1
+	}
+	return 0
+}
+
+// printFindings prints findings followed by their count.
+func printFindings(findings []Finding) {
+	errors := 0
+	for _, f := range findings {
+		fmt.Println(f)
+		if f.Severity == Error {
+			errors++
+		}
+	}
+	if len(findings) == 0 {
+		fmt.Println("report is valid")
+		return
+	}
+	fmt.Printf("%d errors, %d warnings\n", errors, len(findings)-errors)
+}
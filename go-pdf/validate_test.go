@@ -0,0 +1,186 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidateReportData(t *testing.T) {
+	for _, f := range reportData.validate() {
+		if f.Severity == Error {
+			t.Errorf("reportData: %v", f)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(r *Report)
+		severity Severity
+		path     string
+	}{
+		{
+			name:     "materiality out of range",
+			mutate:   func(r *Report) { r.Materiality = 0 },
+			severity: Error,
+			path:     "materiality",
+		},
+		{
+			name: "duplicate facility code",
+			mutate: func(r *Report) {
+				r.Facilities = append(slices.Clone(r.Facilities), r.Facilities[0])
+			},
+			severity: Error,
+			path:     "facilities[3].code",
+		},
+		{
+			name: "fuel not listed",
+			mutate: func(r *Report) {
+				r.FuelRecords = []FuelRecord{{Category: "Mobile Combustion", Source: "น้ำมันดีเซลรถยนต์", Fuel: "ไบโอดีเซล", Gases: map[Gas]float64{CO2: 1}}}
+			},
+			severity: Error,
+			path:     "fuelRecords[0].fuel",
+		},
+		{
+			name: "fossil CH4 from a biogenic fuel",
+			mutate: func(r *Report) {
+				r.Fuels = append(slices.Clone(r.Fuels), Fuel{Name: "ชีวมวล", Origin: Biogenic})
+				r.FuelRecords = []FuelRecord{{Category: "Stationary Combustion", Source: "หม้อไอน้ำชีวมวล", Fuel: "ชีวมวล", Gases: map[Gas]float64{CO2: 1, FossilCH4: 1}}}
+			},
+			severity: Error,
+			path:     "fuelRecords[0].gases",
+		},
+		{
+			name: "CH4 from a fossil fuel",
+			mutate: func(r *Report) {
+				r.FuelRecords = []FuelRecord{{Category: "Mobile Combustion", Source: "น้ำมันดีเซลรถยนต์", Fuel: "น้ำมันดีเซล", Gases: map[Gas]float64{CH4: 1}}}
+			},
+			severity: Error,
+			path:     "fuelRecords[0].gases",
+		},
+		{
+			name: "source without marks",
+			mutate: func(r *Report) {
+				r.Sources = slices.Clone(r.Sources)
+				r.Sources[0].Internal = ""
+			},
+			severity: Error,
+			path:     "sources[0]",
+		},
+		{
+			name: "source listed twice",
+			mutate: func(r *Report) {
+				r.Sources = append(slices.Clone(r.Sources), r.Sources[0])
+			},
+			severity: Warning,
+			path:     "sources[2]",
+		},
+		{
+			name: "override without justification",
+			mutate: func(r *Report) {
+				r.Sources = slices.Clone(r.Sources)
+				r.Sources[0].Override = notSignificant
+			},
+			severity: Error,
+			path:     "sources[0].justification",
+		},
+		{
+			name: "excluded category without reason",
+			mutate: func(r *Report) {
+				r.Scope3 = slices.Clone(r.Scope3)
+				r.Scope3[1].Reason = ""
+			},
+			severity: Error,
+			path:     "scope3[1].reason",
+		},
+		{
+			name: "included category without monitoring",
+			mutate: func(r *Report) {
+				r.Monitoring = slices.DeleteFunc(slices.Clone(r.Monitoring), func(m Monitoring) bool {
+					return sourceKey(m.Source) == sourceKey(WasteGenerated.String())
+				})
+			},
+			severity: Error,
+			path:     "scope3[4]",
+		},
+		{
+			name: "monitoring without origin",
+			mutate: func(r *Report) {
+				r.Monitoring = slices.Clone(r.Monitoring)
+				r.Monitoring[0].Origin = ""
+			},
+			severity: Error,
+			path:     "monitoring[0].origin",
+		},
+		{
+			name: "monitoring with an unknown origin",
+			mutate: func(r *Report) {
+				r.Monitoring = slices.Clone(r.Monitoring)
+				r.Monitoring[0].Origin = "guessed"
+			},
+			severity: Error,
+			path:     "monitoring[0].origin",
+		},
+		{
+			name: "negative denominator",
+			mutate: func(r *Report) {
+				r.Denominators = slices.Clone(r.Denominators)
+				r.Denominators[0].Value = -1
+			},
+			severity: Error,
+			path:     "denominators[0]",
+		},
+		{
+			name:     "base year threshold out of range",
+			mutate:   func(r *Report) { r.BaseYear.Threshold = 1 },
+			severity: Error,
+			path:     "baseYear.threshold",
+		},
+		{
+			name:     "base year scopes not explained",
+			mutate:   func(r *Report) { r.BaseYear.Coverage = "" },
+			severity: Warning,
+			path:     "baseYear",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := reportData
+			tt.mutate(&r)
+			findings := r.validate()
+			for _, f := range findings {
+				if f.Severity == tt.severity && f.Path == tt.path {
+					return
+				}
+			}
+			t.Errorf("no %v at %s in %v", tt.severity, tt.path, findings)
+		})
+	}
+}
+
+// TestValidateSummariesUnrounded checks that rows which each round up in
+// the tables do not make their total look wrong.
+func TestValidateSummariesUnrounded(t *testing.T) {
+	r := reportData
+	r.Records = slices.Clone(r.Records)
+	for i := range r.Records {
+		if r.Records[i].Scope == 3 {
+			r.Records[i].Emissions = 1.004
+		}
+	}
+	for _, f := range r.validate() {
+		if f.Severity == Error {
+			t.Errorf("%v", f)
+		}
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if hasErrors([]Finding{{Severity: Warning}}) {
+		t.Error("warnings reported as errors")
+	}
+	if !hasErrors([]Finding{{Severity: Warning}, {Severity: Error}}) {
+		t.Error("error not reported")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Watermark marks pages of a draft, e.g. "ฉบับร่าง / DRAFT", as rotated
+// semi-transparent text with the revision and the time it was made, or as
+// an image when Image is set. It is drawn behind the page content from the
+// header callback, or over it from the footer callback when Above is set.
+type Watermark struct {
+	Text     string
+	Revision string
+	Stamp    string // when the draft was made, e.g. "2023-06-28 09:00"
+	Image    string
+	Above    bool
+	// Pages selects the pages by their number in the file, the cover being
+	// page 1; nil marks every page.
+	Pages func(page int) bool
+}
+
+const (
+	watermarkAlpha    = 0.2
+	watermarkAngle    = 45.0
+	watermarkFontSize = 72.0
+	watermarkLineSize = 24.0
+	// watermarkImageWidth is the share of the page width an image takes.
+	watermarkImageWidth = 0.6
+)
+
+// parsePageRanges parses a page selection such as "1,3-5,10-" into a
+// function that reports whether a page is selected. An empty selection
+// selects every page.
+func parsePageRanges(s string) (func(page int) bool, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	type pageRange struct{ first, last int } // last is 0 for open ranges
+	var ranges []pageRange
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		var r pageRange
+		var err error
+		if r.first, err = strconv.Atoi(first); err != nil || r.first < 1 {
+			return nil, fmt.Errorf("bad page range %q", part)
+		}
+		r.last = r.first
+		if isRange {
+			r.last = 0
+			if last != "" {
+				if r.last, err = strconv.Atoi(last); err != nil || r.last < r.first {
+					return nil, fmt.Errorf("bad page range %q", part)
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return func(page int) bool {
+		for _, r := range ranges {
+			if page >= r.first && (r.last == 0 || page <= r.last) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// lines returns the text lines of the watermark: the text, then the
+// revision and stamp.
+func (wm *Watermark) lines() []string {
+	var details []string
+	if wm.Revision != "" {
+		details = append(details, "Revision "+wm.Revision)
+	}
+	if wm.Stamp != "" {
+		details = append(details, wm.Stamp)
+	}
+	lines := []string{wm.Text}
+	if len(details) > 0 {
+		lines = append(lines, strings.Join(details, ", "))
+	}
+	return lines
+}
+
+// draw draws the watermark on the current page when it belongs in the
+// layer given by above. It is called from both the header and the footer
+// callback and leaves the position as it found it.
+func (wm *Watermark) draw(pdf *gofpdf.Fpdf, above bool) {
+	if wm == nil || wm.Above != above || (wm.Pages != nil && !wm.Pages(pdf.PageNo())) {
+		return
+	}
+	x, y := pdf.GetXY()
+	w, h := pdf.GetPageSize()
+	cx, cy := w/2, h/2
+
+	pdf.SetAlpha(watermarkAlpha, "Normal")
+	pdf.TransformBegin()
+	pdf.TransformRotate(watermarkAngle, cx, cy)
+	if wm.Image != "" {
+		if info := pdf.RegisterImageOptions(wm.Image, gofpdf.ImageOptions{}); info != nil {
+			iw, ih := fitImage(info.Width(), info.Height(), w*watermarkImageWidth, h*watermarkImageWidth)
+			pdf.ImageOptions(wm.Image, cx-iw/2, cy-ih/2, iw, ih, false, gofpdf.ImageOptions{}, 0, "")
+		}
+	} else {
+		r, g, b := pdf.GetTextColor()
+		pdf.SetTextColor(128, 128, 128)
+		lines := wm.lines()
+		lineHeight := func(i int) float64 {
+			if i == 0 {
+				return watermarkFontSize / pdf.GetConversionRatio()
+			}
+			return watermarkLineSize / pdf.GetConversionRatio()
+		}
+		top := cy
+		for i := range lines {
+			top -= lineHeight(i) / 2
+		}
+		for i, line := range lines {
+			if i == 0 {
+				pdf.SetFont("THSarabunNew", "B", watermarkFontSize)
+			} else {
+				pdf.SetFont("THSarabunNew", "", watermarkLineSize)
+			}
+			pdf.SetXY(0, top)
+			pdf.CellFormat(w, lineHeight(i), line, "", 0, "C", false, 0, "")
+			top += lineHeight(i)
+		}
+		pdf.SetTextColor(r, g, b)
+	}
+	pdf.TransformEnd()
+	pdf.SetAlpha(1, "Normal")
+	pdf.SetXY(x, y)
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestParsePageRanges(t *testing.T) {
+	tests := []struct {
+		s        string
+		selected []int
+		skipped  []int
+	}{
+		{"", []int{1, 2, 100}, nil},
+		{"1,3-5,10-", []int{1, 3, 4, 5, 10, 50}, []int{2, 6, 9}},
+		{" 2 , 4 ", []int{2, 4}, []int{1, 3, 5}},
+	}
+	for _, tt := range tests {
+		selected, err := parsePageRanges(tt.s)
+		if err != nil {
+			t.Errorf("parsePageRanges(%q): %v", tt.s, err)
+			continue
+		}
+		for _, page := range tt.selected {
+			if selected != nil && !selected(page) {
+				t.Errorf("parsePageRanges(%q) skips page %d", tt.s, page)
+			}
+		}
+		for _, page := range tt.skipped {
+			if selected(page) {
+				t.Errorf("parsePageRanges(%q) selects page %d", tt.s, page)
+			}
+		}
+	}
+
+	for _, s := range []string{"0", "x", "5-2", "1,,2", "-3"} {
+		if _, err := parsePageRanges(s); err == nil {
+			t.Errorf("parsePageRanges(%q) accepted", s)
+		}
+	}
+}